@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/iksnevil/passcomb/pkg/generator"
 	"github.com/iksnevil/passcomb/pkg/interactive"
@@ -14,6 +20,18 @@ type CLI struct {
 	config generator.Config
 }
 
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func NewCLI() *CLI {
 	return &CLI{
 		config: generator.Config{
@@ -29,19 +47,65 @@ func (c *CLI) ParseArgs(args []string) error {
 	var (
 		inputFile       = flags.String("input", "", "Input file with passwords (one per line)")
 		outputFile      = flags.String("output", "", "Output file for combinations")
-		combinationSize = flags.Int("count", 2, "Combination size (2-4)")
+		combinationSize = flags.Int("count", 2, "Combination size")
+		minCount        = flags.Int("min-count", 0, "Smallest combination size when generating a range of sizes")
+		maxCount        = flags.Int("max-count", 0, "Largest combination size when generating a range of sizes")
+		mode            = flags.String("mode", "product", "Combination mode: product, permutation, combination")
+		template        = flags.String("template", "", "Candidate template, e.g. '{w}{sep}{w|cap}{year}{sym}'")
 		extraSymbols    = flags.String("symbols", "", "Extra symbols to use (e.g., '!@#$')")
-		positions       = flags.String("positions", "", "Symbol positions: start,end,between")
+		positions       = flags.String("positions", "", "Symbol positions: start,end,between,between-all,gap1..gapN")
+		maxSymbols      = flags.Int("max-symbols", 1, "Insert up to N symbols at different positions of one candidate")
+		prefixes        = flags.String("prefixes", "", "Comma-separated strings prepended to combinations (e.g., '123,!!')")
+		suffixes        = flags.String("suffixes", "", "Comma-separated strings appended to combinations (e.g., '@2024,_admin')")
+		prefixFile      = flags.String("prefix-file", "", "Wordlist of prefixes")
+		suffixFile      = flags.String("suffix-file", "", "Wordlist of suffixes")
+		suffixRanges    = flags.String("suffix-range", "", "Comma-separated number ranges appended like suffixes (e.g., '0-99,1950-2030')")
+		separators      = flags.String("separators", "", "Separators inserted between words (e.g., '-_.'), no separator is always included")
+		sameSeparator   = flags.Bool("same-separator", false, "Use the same separator at every gap of a candidate")
 		maxFileSize     = flags.Int("maxsize", 100, "Max file size in MB")
+		skip            = flags.Int64("skip", 0, "Skip the first N candidates of the keyspace")
+		limit           = flags.Int64("limit", 0, "Stop after N candidates (0 = no limit)")
+		shard           = flags.String("shard", "", "Generate only shard K of N (e.g., '3/8')")
+		checkpoint      = flags.String("checkpoint", "", "Checkpoint file (default: <output>.checkpoint, 'none' to disable)")
+		resume          = flags.String("resume", "", "Resume an interrupted run from a checkpoint file")
+		leet            = flags.String("leet", "", "Leetspeak variants of every word: all, subsets, max:K")
+		leetTable       = flags.String("leet-table", "", "Leet substitution table, one CHAR=SUBSTITUTE per line")
+		rules           = flags.String("rules", "", "Hashcat rule file applied to every candidate")
+		rulesPerWord    = flags.Bool("rules-per-word", false, "Apply --rules to every word before combining instead")
+		dropRejected    = flags.Bool("drop-rejected", false, "Skip empty, over-long and repeated rule outputs of a candidate")
+		slotsFile       = flags.String("slots", "", "File listing one wordlist per slot")
 		showHelp        = flags.Bool("help", false, "Show help")
+		slots           stringList
+		maskSlots       stringList
+		rangeSlots      stringList
+		dateSlots       stringList
+		walkSlots       stringList
+		caseMutations   stringList
+		layouts         stringList
+		translits       stringList
+		charsets        [4]string
 	)
+	flags.Var(&slots, "slot", "Wordlist for the next slot, repeat once per slot ('input' for the input file)")
+	flags.Var(&maskSlots, "mask-slot", "Hashcat-style mask for slot K, e.g. '2=?d?d?s' (repeatable)")
+	flags.Var(&rangeSlots, "range-slot", "Number range for slot K, e.g. '3=1950-2030' (repeatable)")
+	flags.Var(&layouts, "layout", "Keyboard layouts every word is transposed to and from QWERTY: "+strings.Join(generator.LayoutNames(), ",")+" (repeatable)")
+	flags.Var(&translits, "translit", "Latin variants of Cyrillic and Greek words: gost,iso9,informal,greek (repeatable)")
+	flags.Var(&caseMutations, "case", "Case variants of every word: lower,upper,cap,invcap,toggle,perm:N (repeatable)")
+	flags.Var(&dateSlots, "date-slot", "Dates for slot K, e.g. '2=1970..2005:DDMMYYYY,DDMM' (repeatable)")
+	flags.Var(&walkSlots, "walk-slot", "Keyboard walks for slot K, e.g. '2=4-8:repeat,shift=both' (repeatable)")
+
+	for i := range charsets {
+		usage := fmt.Sprintf("Custom charset ?%d: characters, ranges (a-z), charsets (?l) or a .hcchr file", i+1)
+		flags.StringVar(&charsets[i], fmt.Sprintf("custom-charset%d", i+1), "", usage)
+		flags.StringVar(&charsets[i], strconv.Itoa(i+1), "", usage)
+	}
 
 	// Define short aliases
 	flags.StringVar(inputFile, "i", "", "Input file with passwords (one per line)")
 	flags.StringVar(outputFile, "o", "", "Output file for combinations")
-	flags.IntVar(combinationSize, "c", 2, "Combination size (2-4)")
+	flags.IntVar(combinationSize, "c", 2, "Combination size")
 	flags.StringVar(extraSymbols, "s", "", "Extra symbols to use (e.g., '!@#$')")
-	flags.StringVar(positions, "p", "", "Symbol positions: start,end,between")
+	flags.StringVar(positions, "p", "", "Symbol positions: start,end,between,between-all,gap1..gapN")
 	flags.IntVar(maxFileSize, "m", 100, "Max file size in MB")
 	flags.BoolVar(showHelp, "h", false, "Show help")
 
@@ -56,11 +120,112 @@ func (c *CLI) ParseArgs(args []string) error {
 
 	// Determine mode: if any CLI parameters are provided, use CLI mode, otherwise interactive
 	hasCLIParams := *inputFile != "" || *outputFile != "" || *combinationSize != 2 ||
-		*extraSymbols != "" || *positions != "" || *maxFileSize != 100
+		*minCount != 0 || *maxCount != 0 || *mode != "product" || *template != "" ||
+		*extraSymbols != "" || *positions != "" || *maxSymbols != 1 || *separators != "" || *sameSeparator ||
+		*prefixes != "" || *suffixes != "" || *prefixFile != "" || *suffixFile != "" || *maxFileSize != 100 ||
+		*leet != "" || *leetTable != "" || *rules != "" || *rulesPerWord || *dropRejected || *skip != 0 || *limit != 0 || *shard != "" || *checkpoint != "" ||
+		len(slots) > 0 || *slotsFile != "" || len(maskSlots) > 0 || len(rangeSlots) > 0 || len(dateSlots) > 0 || len(walkSlots) > 0 || len(caseMutations) > 0 || len(layouts) > 0 || len(translits) > 0 || *suffixRanges != "" || charsets != [4]string{}
+
+	if *resume != "" {
+		// All options are restored from the checkpoint
+		if hasCLIParams {
+			return fmt.Errorf("--resume cannot be combined with other options")
+		}
+
+		cp, err := generator.LoadCheckpoint(*resume)
+		if err != nil {
+			return err
+		}
+		c.config = cp.Config
+		c.config.CheckpointFile = *resume
+		c.config.Resume = true
+		return nil
+	}
 
 	if hasCLIParams {
+		// Parse custom charsets; each may refer to the ones before it
+		for i, def := range charsets {
+			if def == "" {
+				continue
+			}
+			chars, err := parseCharset(def, c.config.Charsets)
+			if err != nil {
+				return fmt.Errorf("invalid custom charset %d: %w", i+1, err)
+			}
+			c.config.Charsets[i] = chars
+		}
+
+		// Parse per-slot wordlists
+		if *slotsFile != "" {
+			specs, err := readSlotsFile(*slotsFile)
+			if err != nil {
+				return err
+			}
+			slots = append(specs, slots...)
+		}
+		for _, value := range slots {
+			spec, err := generator.ParseSlotSpec(value)
+			if err != nil {
+				return err
+			}
+			c.config.Slots = append(c.config.Slots, spec)
+		}
+
+		// An explicit --count above the number of --slot values fills the positions after the last slot
+		// from the input file
+		countSet := false
+		flags.Visit(func(f *flag.Flag) {
+			countSet = countSet || f.Name == "count" || f.Name == "c"
+		})
+		if countSet && len(c.config.Slots) > 0 {
+			if *combinationSize < len(c.config.Slots) {
+				return fmt.Errorf("--count %d is less than the %d --slot values", *combinationSize, len(c.config.Slots))
+			}
+			for len(c.config.Slots) < *combinationSize {
+				c.config.Slots = append(c.config.Slots, generator.SlotSpec{})
+			}
+		}
+
+		// Parse mask, range, date and walk slots; without --slot the other positions use the input file
+		if len(maskSlots)+len(rangeSlots)+len(dateSlots)+len(walkSlots) > 0 && len(c.config.Slots) == 0 {
+			c.config.Slots = make([]generator.SlotSpec, max(*combinationSize, 0))
+		}
+		indexed := []struct {
+			kind   string
+			values []string
+		}{{"mask", maskSlots}, {"range", rangeSlots}, {"date", dateSlots}, {"walk", walkSlots}}
+		for _, slots := range indexed {
+			for _, value := range slots.values {
+				index, spec, err := parseIndexedSlot(slots.kind, value)
+				if err != nil {
+					return err
+				}
+				if index > len(c.config.Slots) {
+					return fmt.Errorf("invalid %s slot %d: the combination size is %d", slots.kind, index, len(c.config.Slots))
+				}
+				c.config.Slots[index-1] = spec
+			}
+		}
+
+		// Input slots and positions past the last slot draw from the input file
+		needsInput := len(c.config.Slots) == 0 || slices.ContainsFunc(c.config.Slots, generator.SlotSpec.IsZero) ||
+			max(*minCount, *maxCount) > len(c.config.Slots)
+
+		// Parse template
+		if *template != "" {
+			if *combinationSize != 2 || *minCount != 0 || *maxCount != 0 || len(c.config.Slots) > 0 || *positions != "" {
+				return fmt.Errorf("--template cannot be combined with --count, --min-count, --max-count, --slot(s) or --positions")
+			}
+			tmpl, err := generator.ParseTemplate(*template)
+			if err != nil {
+				return err
+			}
+			c.config.Template = *template
+			needsInput = tmpl.UsesInput()
+		}
+
 		// CLI mode - validate required parameters
-		if *inputFile == "" {
+		if *inputFile == "" && needsInput {
 			return fmt.Errorf("input file is required in CLI mode")
 		}
 		if *outputFile == "" {
@@ -70,39 +235,245 @@ func (c *CLI) ParseArgs(args []string) error {
 		c.config.InputFile = *inputFile
 		c.config.OutputFile = *outputFile
 		c.config.CombinationSize = *combinationSize
+		if len(c.config.Slots) > 0 {
+			c.config.CombinationSize = len(c.config.Slots)
+		}
 		c.config.MaxFileSizeMB = *maxFileSize
 
+		if *skip < 0 {
+			return fmt.Errorf("skip must not be negative")
+		}
+		if *limit < 0 {
+			return fmt.Errorf("limit must not be negative")
+		}
+		c.config.Skip = *skip
+		c.config.Limit = *limit
+
+		switch *checkpoint {
+		case "":
+			c.config.CheckpointFile = *outputFile + ".checkpoint"
+		case "none":
+			c.config.CheckpointFile = ""
+		default:
+			c.config.CheckpointFile = *checkpoint
+		}
+
+		// Parse shard
+		if *shard != "" {
+			index, count, err := parseShard(*shard)
+			if err != nil {
+				return err
+			}
+			c.config.ShardIndex = index
+			c.config.ShardCount = count
+		}
+
+		// Parse combination mode
+		parsedMode, err := generator.ParseMode(*mode)
+		if err != nil {
+			return err
+		}
+		c.config.Mode = parsedMode
+
+		// Parse keyboard layouts
+		for _, value := range layouts {
+			for _, name := range parseList(value) {
+				if !slices.Contains(c.config.Layouts, name) {
+					c.config.Layouts = append(c.config.Layouts, name)
+				}
+			}
+		}
+
+		// Parse transliterations
+		for _, value := range translits {
+			for _, name := range parseList(value) {
+				t, err := generator.ParseTransliteration(name)
+				if err != nil {
+					return err
+				}
+				if !slices.Contains(c.config.Transliterations, t) {
+					c.config.Transliterations = append(c.config.Transliterations, t)
+				}
+			}
+		}
+
+		// Parse case mutations
+		for _, value := range caseMutations {
+			for _, name := range parseList(value) {
+				m, err := generator.ParseCaseMutation(name)
+				if err != nil {
+					return err
+				}
+				if !slices.Contains(c.config.CaseMutations, m) {
+					c.config.CaseMutations = append(c.config.CaseMutations, m)
+				}
+			}
+		}
+
+		// Parse leet mode
+		if *leet != "" {
+			leetMode, err := generator.ParseLeetMode(*leet)
+			if err != nil {
+				return err
+			}
+			c.config.Leet = leetMode
+		}
+		if *leetTable != "" && c.config.Leet == generator.LeetNone {
+			return fmt.Errorf("--leet-table requires --leet")
+		}
+		c.config.LeetTable = *leetTable
+
+		// Parse rules
+		if (*rulesPerWord || *dropRejected) && *rules == "" {
+			return fmt.Errorf("--rules-per-word and --drop-rejected require --rules")
+		}
+		if *rulesPerWord && *dropRejected {
+			return fmt.Errorf("--drop-rejected applies to candidate rules, per-word rule outputs are always deduplicated")
+		}
+		c.config.RuleFile = *rules
+		c.config.RulesPerWord = *rulesPerWord
+		c.config.DropRejected = *dropRejected
+
 		// Parse extra symbols
 		if *extraSymbols != "" {
 			c.config.ExtraSymbols = []rune(*extraSymbols)
 		}
 
+		if *maxSymbols < 1 {
+			return fmt.Errorf("max-symbols must be at least 1")
+		}
+		c.config.MaxSymbols = *maxSymbols
+
+		// Parse affixes
+		c.config.Prefixes = parseList(*prefixes)
+		c.config.Suffixes = parseList(*suffixes)
+		c.config.PrefixFile = *prefixFile
+		c.config.SuffixFile = *suffixFile
+		c.config.SuffixRanges = parseList(*suffixRanges)
+
+		// Parse separators
+		if *separators != "" {
+			c.config.Separators = []rune(*separators)
+		}
+		c.config.SameSeparator = *sameSeparator
+
 		// Parse symbol positions
 		if *positions != "" {
-			posList := strings.Split(*positions, ",")
-			for _, pos := range posList {
-				switch strings.TrimSpace(pos) {
-				case "start":
-					c.config.SymbolPositions = append(c.config.SymbolPositions, generator.PositionStart)
-				case "end":
-					c.config.SymbolPositions = append(c.config.SymbolPositions, generator.PositionEnd)
-				case "between":
-					c.config.SymbolPositions = append(c.config.SymbolPositions, generator.PositionBetween)
-				default:
-					return fmt.Errorf("invalid position: %s (valid: start, end, between)", pos)
+			for _, pos := range parseList(*positions) {
+				position, err := generator.ParseSymbolPosition(pos)
+				if err != nil {
+					return err
 				}
+				c.config.SymbolPositions = append(c.config.SymbolPositions, position)
 			}
 		}
 
 		// Validate combination size
-		if c.config.CombinationSize < 2 || c.config.CombinationSize > 4 {
-			return fmt.Errorf("combination size must be between 2 and 4")
+		if c.config.CombinationSize < 1 {
+			return fmt.Errorf("combination size must be at least 1")
+		}
+		if err := generator.NewGenerator(c.config).Validate(); err != nil {
+			return err
+		}
+
+		if *minCount != 0 || *maxCount != 0 {
+			c.config.MinCombinationSize = *minCount
+			if c.config.MinCombinationSize == 0 {
+				c.config.MinCombinationSize = c.config.CombinationSize
+			}
+			c.config.MaxCombinationSize = *maxCount
+			if c.config.MaxCombinationSize == 0 {
+				c.config.MaxCombinationSize = max(c.config.MinCombinationSize, c.config.CombinationSize)
+			}
+
+			if c.config.MinCombinationSize < 1 {
+				return fmt.Errorf("min-count must be at least 1")
+			}
+			if c.config.MaxCombinationSize < c.config.MinCombinationSize {
+				return fmt.Errorf("max-count must not be less than min-count")
+			}
 		}
 	}
 
 	return nil
 }
 
+// parseList splits a comma-separated list, dropping empty items.
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCharset reads a .hcchr file or expands an inline charset definition.
+func parseCharset(def string, custom [4]string) (string, error) {
+	if strings.HasSuffix(def, ".hcchr") {
+		return generator.ReadCharsetFile(def)
+	}
+	return generator.ParseCharset(def, custom)
+}
+
+// parseIndexedSlot parses a K=VALUE slot of the given kind, e.g. a mask
+// slot 2=?d?d?s, a range slot 3=1950-2030, a date slot 2=1970..2005 or a walk
+// slot 1=4-8.
+func parseIndexedSlot(kind, value string) (int, generator.SlotSpec, error) {
+	indexStr, arg, ok := strings.Cut(value, "=")
+	index, err := strconv.Atoi(strings.TrimSpace(indexStr))
+	if !ok || err != nil || index < 1 {
+		return 0, generator.SlotSpec{}, fmt.Errorf("invalid %s slot: %s (expected K=%s)", kind, value, strings.ToUpper(kind))
+	}
+
+	spec, err := generator.ParseSlotSpec(kind + ":" + arg)
+	if err != nil {
+		return 0, generator.SlotSpec{}, fmt.Errorf("invalid %s slot %d: %w", kind, index, err)
+	}
+	return index, spec, nil
+}
+
+// readSlotsFile reads one slot per line, skipping blank lines and # comments.
+func readSlotsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read slots file: %w", err)
+	}
+
+	var slots []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		slots = append(slots, line)
+	}
+	return slots, nil
+}
+
+func parseShard(value string) (int, int, error) {
+	indexStr, countStr, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid shard: %s (expected K/N, e.g. 3/8)", value)
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(indexStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard: %s (expected K/N, e.g. 3/8)", value)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard: %s (expected K/N, e.g. 3/8)", value)
+	}
+
+	if count < 1 || index < 1 || index > count {
+		return 0, 0, fmt.Errorf("invalid shard: %s (K must be between 1 and N)", value)
+	}
+
+	return index, count, nil
+}
+
 func (c *CLI) Run() error {
 	// Determine mode: if any CLI parameters are provided, use CLI mode, otherwise interactive
 	if c.config.InputFile != "" || c.config.OutputFile != "" {
@@ -123,47 +494,152 @@ func (c *CLI) runCLI() error {
 	gen := generator.NewGenerator(c.config)
 
 	// Load passwords
-	fmt.Printf("Loading passwords from: %s\n", c.config.InputFile)
+	if c.config.InputFile != "" {
+		fmt.Printf("Loading passwords from: %s\n", c.config.InputFile)
+	}
 	if err := gen.LoadPasswords(); err != nil {
 		return fmt.Errorf("failed to load passwords: %w", err)
 	}
 
-	passwordCount := gen.GetPasswordCount()
-	fmt.Printf("Loaded %d passwords\n", passwordCount)
+	if c.config.InputFile != "" {
+		passwordCount := gen.GetPasswordCount()
+		fmt.Printf("Loaded %d passwords\n", passwordCount)
+		if variants := gen.GetVariantCount(); variants != passwordCount && passwordCount > 0 {
+			fmt.Printf("With variants: %d words (x%.2f)\n", variants, float64(variants)/float64(passwordCount))
+		}
+	}
+	if c.config.RuleFile != "" {
+		fmt.Printf("Loaded %d rules from: %s\n", gen.GetRuleCount(), c.config.RuleFile)
+	}
+	sources := gen.SlotSourceCounts()
+	for i, count := range gen.SlotWordCounts() {
+		if count != sources[i] && sources[i] > 0 {
+			fmt.Printf("Slot %d: %s (%d words, with variants: %d words (x%.2f))\n",
+				i+1, c.config.Slots[i], sources[i], count, float64(count)/float64(sources[i]))
+			continue
+		}
+		fmt.Printf("Slot %d: %s (%d words)\n", i+1, c.config.Slots[i], count)
+	}
 
 	// Calculate combinations
-	totalCombinations := gen.CalculateTotalCombinations()
-	fmt.Printf("Total combinations to generate: %d\n", totalCombinations)
+	totalCombinations, err := gen.CalculateTotalCombinations()
+	if err != nil {
+		fmt.Printf("Keyspace size: %s\n", gen.Keyspace())
+		return err
+	}
+	if c.config.Skip > 0 || c.config.Limit > 0 || c.config.ShardCount > 0 {
+		start, end, _ := gen.Range()
+		fmt.Printf("Keyspace size: %s\n", gen.Keyspace())
+		if c.config.ShardCount > 0 {
+			fmt.Printf("Shard: %d/%d\n", c.config.ShardIndex, c.config.ShardCount)
+		}
+		fmt.Printf("Candidate range: [%d, %d)\n", start, end)
+	}
+	if c.config.DropRejected {
+		fmt.Printf("Total combinations to generate: at most %d\n", totalCombinations)
+	} else {
+		fmt.Printf("Total combinations to generate: %d\n", totalCombinations)
+	}
 
 	// Show configuration
 	fmt.Printf("\nConfiguration:\n")
-	fmt.Printf("  Combination size: %d\n", c.config.CombinationSize)
+	if c.config.Template != "" {
+		fmt.Printf("  Template: %s\n", c.config.Template)
+	} else if c.config.MinCombinationSize > 0 && c.config.MinCombinationSize != c.config.MaxCombinationSize {
+		fmt.Printf("  Combination size: %d-%d\n", c.config.MinCombinationSize, c.config.MaxCombinationSize)
+	} else if c.config.MinCombinationSize > 0 {
+		fmt.Printf("  Combination size: %d\n", c.config.MinCombinationSize)
+	} else {
+		fmt.Printf("  Combination size: %d\n", c.config.CombinationSize)
+	}
+	fmt.Printf("  Mode: %s\n", c.config.Mode)
+	if len(c.config.Layouts) > 0 {
+		fmt.Printf("  Layout variants: %s\n", strings.Join(c.config.Layouts, ", "))
+	}
+	if len(c.config.Transliterations) > 0 {
+		var schemes []string
+		for _, t := range c.config.Transliterations {
+			schemes = append(schemes, t.String())
+		}
+		fmt.Printf("  Transliteration: %s\n", strings.Join(schemes, ", "))
+	}
+	if len(c.config.CaseMutations) > 0 {
+		var mutations []string
+		for _, m := range c.config.CaseMutations {
+			mutations = append(mutations, m.String())
+		}
+		fmt.Printf("  Case variants: %s\n", strings.Join(mutations, ", "))
+	}
+	if c.config.Leet != generator.LeetNone {
+		table := "default table"
+		if c.config.LeetTable != "" {
+			table = "table " + c.config.LeetTable
+		}
+		fmt.Printf("  Leet: %s (%s)\n", c.config.Leet, table)
+	}
+	if c.config.RuleFile != "" {
+		switch {
+		case c.config.RulesPerWord:
+			fmt.Printf("  Rules: %s (every word)\n", c.config.RuleFile)
+		case c.config.DropRejected:
+			fmt.Printf("  Rules: %s (every candidate, dropping rejected and repeated outputs)\n", c.config.RuleFile)
+		default:
+			fmt.Printf("  Rules: %s (every candidate)\n", c.config.RuleFile)
+		}
+	}
+	for i, chars := range c.config.Charsets {
+		if chars != "" {
+			fmt.Printf("  Custom charset ?%d: %q\n", i+1, chars)
+		}
+	}
 	if len(c.config.ExtraSymbols) > 0 {
 		fmt.Printf("  Extra symbols: %s\n", string(c.config.ExtraSymbols))
 		var positions []string
 		for _, pos := range c.config.SymbolPositions {
-			switch pos {
-			case generator.PositionStart:
-				positions = append(positions, "start")
-			case generator.PositionEnd:
-				positions = append(positions, "end")
-			case generator.PositionBetween:
-				positions = append(positions, "between")
-			}
+			positions = append(positions, pos.String())
+		}
+		if len(positions) > 0 {
+			fmt.Printf("  Symbol positions: %s\n", strings.Join(positions, ", "))
+		}
+		if c.config.MaxSymbols > 1 {
+			fmt.Printf("  Max symbols per candidate: %d\n", c.config.MaxSymbols)
+		}
+	}
+	if prefixes := affixSummary(c.config.Prefixes, c.config.PrefixFile, nil); prefixes != "" {
+		fmt.Printf("  Prefixes: %s\n", prefixes)
+	}
+	if suffixes := affixSummary(c.config.Suffixes, c.config.SuffixFile, c.config.SuffixRanges); suffixes != "" {
+		fmt.Printf("  Suffixes: %s\n", suffixes)
+	}
+	if len(c.config.Separators) > 0 {
+		if c.config.SameSeparator {
+			fmt.Printf("  Separators: %s (same at every gap)\n", string(c.config.Separators))
+		} else {
+			fmt.Printf("  Separators: %s\n", string(c.config.Separators))
 		}
-		fmt.Printf("  Symbol positions: %s\n", strings.Join(positions, ", "))
 	}
 	fmt.Printf("  Max file size: %d MB\n", c.config.MaxFileSizeMB)
 
+	if c.config.CheckpointFile != "" {
+		fmt.Printf("  Checkpoint: %s\n", c.config.CheckpointFile)
+	}
+
 	// Generate combinations
-	fmt.Printf("\nGenerating combinations...\n")
+	if c.config.Resume {
+		fmt.Printf("\nResuming generation from checkpoint...\n")
+	} else {
+		fmt.Printf("\nGenerating combinations...\n")
+	}
+
+	// Stop on Ctrl-C, saving a checkpoint so the run can be resumed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	var genErr error
 	progressChan := make(chan generator.ProgressInfo)
 	go func() {
 		defer close(progressChan)
-		if err := gen.GenerateCombinations(progressChan); err != nil {
-			fmt.Printf("Error during generation: %v\n", err)
-		}
+		genErr = gen.GenerateCombinationsContext(ctx, progressChan)
 	}()
 
 	// Simple progress display
@@ -173,10 +649,29 @@ func (c *CLI) runCLI() error {
 			percent, progress.Generated, progress.TotalCombinations, progress.CurrentFile)
 	}
 
+	if errors.Is(genErr, context.Canceled) && c.config.CheckpointFile != "" {
+		fmt.Printf("\n\nGeneration interrupted. Resume with: passcomb --resume %s\n", c.config.CheckpointFile)
+		return nil
+	}
+	if genErr != nil {
+		return fmt.Errorf("error during generation: %w", genErr)
+	}
+
 	fmt.Printf("\n\nGeneration complete!\n")
 	return nil
 }
 
+func affixSummary(inline []string, file string, ranges []string) string {
+	items := slices.Clone(inline)
+	if file != "" {
+		items = append(items, "words of "+file)
+	}
+	for _, r := range ranges {
+		items = append(items, "numbers "+r)
+	}
+	return strings.Join(items, ", ")
+}
+
 func (c *CLI) showHelp() {
 	fmt.Printf(`passcomb - Password Combination Generator
 
@@ -194,10 +689,62 @@ MODES:
 CLI OPTIONS:
     -i, --input string     Input file with passwords (one per line) [required in CLI mode]
     -o, --output string    Output file for combinations [required in CLI mode]
-    -c, --count int        Combination size (2-4) [default: 2]
-    -s, --symbols string   Extra symbols to use (e.g., '!@#$') [default: none]
-    -p, --positions string Symbol positions: start,end,between [default: none]
+    -c, --count int        Combination size [default: 2]
+        --min-count int    Smallest size when generating a range of sizes [default: count]
+        --max-count int    Largest size when generating a range of sizes [default: count]
+        --mode string      Combination mode: product, permutation, combination [default: product]
+        --layout list      Keyboard layouts every word is transposed to and from QWERTY:
+                           by, de, fr, ru, ua (comma-separated or repeated) [default: none]
+        --translit list    Latin variants of Cyrillic and Greek words: gost, iso9, informal,
+                           greek (comma-separated or repeated) [default: none]
+        --case list        Case variants added for every word: lower, upper, cap, invcap,
+                           toggle, perm:N (comma-separated or repeated) [default: none]
+        --leet string      Leetspeak variants added for every word: all, subsets, max:K
+        --leet-table file  Leet substitution table, one CHAR=SUBSTITUTE per line
+                           [default: built-in table]
+        --rules file       Hashcat rule file applied to every candidate [default: none]
+        --rules-per-word   Apply --rules to every word before combining instead
+        --drop-rejected    Skip empty, over-long and repeated rule outputs of a candidate
+        --slot file        Wordlist for the next slot, repeat once per slot; 'input' uses
+                           the input file. The combination size is the number of slots,
+                           or -c if larger: the positions after the last slot use the
+                           input file
+        --slots file       File listing one --slot value per line
+        --mask-slot K=MASK Make slot K a hashcat-style mask, e.g. 2='?d?d?s' (repeatable)
+        --range-slot K=N-M Make slot K the numbers N to M, e.g. 3=1950-2030 (repeatable)
+        --date-slot K=FROM..TO[:FORMATS]
+                           Make slot K the dates FROM to TO in each format, e.g.
+                           2=1970..2005:DDMMYYYY,DD.MM.YY (repeatable)
+        --walk-slot K=MIN-MAX[:OPTIONS]
+                           Make slot K the keyboard walks of MIN to MAX keys, e.g.
+                           2=4-8:repeat,shift=both (repeatable)
+        --template string  Candidate template, replaces --count, --slot and --positions
+                           (see TEMPLATES)
+    -1, -2, -3, -4 charset Custom charsets ?1 to ?4 for masks, symbols and templates,
+                           e.g. -1 'aeiou' -2 '!@#$' -3 'a-f?d', or a .hcchr file
+                           (long names: --custom-charset1 to --custom-charset4)
+    -s, --symbols string   Extra symbols to use (e.g., '!@#$', '?1?d') [default: none]
+    -p, --positions string Symbol positions: start,end,between,between-all,gap1..gapN
+                           [default: none]
+        --max-symbols int  Insert up to N symbols at once, one per position [default: 1]
+        --prefixes list    Comma-separated strings to prepend (e.g., '123,!!') [default: none]
+        --suffixes list    Comma-separated strings to append (e.g., '@2024,_admin') [default: none]
+        --prefix-file file Wordlist of prefixes, added to --prefixes
+        --suffix-file file Wordlist of suffixes, added to --suffixes
+        --suffix-range list
+                           Comma-separated number ranges added to the suffixes
+                           (e.g., '0-99,1950-2030')
+        --separators string
+                           Separators inserted between words, plus no separator
+                           (e.g., '-_.') [default: none]
+        --same-separator   Use the same separator at every gap of a candidate
     -m, --maxsize int      Max file size in MB [default: 100]
+        --skip int         Skip the first N candidates of the keyspace [default: 0]
+        --limit int        Stop after N candidates, 0 = no limit [default: 0]
+        --shard K/N        Generate only the K-th of N disjoint shards [default: none]
+        --checkpoint file  Periodically save progress to file, 'none' to disable
+                           [default: <output>.checkpoint]
+        --resume file      Continue an interrupted run from its checkpoint file
     -h, --help             Show this help message
 
 EXAMPLES:
@@ -213,13 +760,249 @@ EXAMPLES:
     # CLI mode - with extra symbols (long names)
     passcomb --input passwords.txt --output combos.txt --count 4 --symbols '!@#' --positions start,end
 
+    # CLI mode - every size from 1 to 6 word(s), grouped by size
+    passcomb -i passwords.txt -o combos.txt --min-count 1 --max-count 6
+
+    # CLI mode - passphrases that never repeat a word
+    passcomb -i words.txt -o phrases.txt -c 4 --mode permutation
+
+    # CLI mode - Russian words as typed on a QWERTY layout (пароль -> gfhjkm)
+    passcomb -i words-ru.txt -o combos.txt -c 2 --layout ru
+
+    # CLI mode - Russian words spelled in Latin letters (Юля -> Yulya, Julia, ...)
+    passcomb -i words-ru.txt -o combos.txt -c 2 --translit gost,informal
+
+    # CLI mode - JohnSmith, johnSMITH, ... from john and smith
+    passcomb -i names.txt -o combos.txt -c 2 --case lower,upper,cap
+
+    # CLI mode - apply a hashcat rule file to every candidate
+    passcomb -i words.txt -o combos.txt --rules best64.rule --drop-rejected
+
+    # CLI mode - p4ssword, passw0rd, ... with up to two substitutions per word
+    passcomb -i words.txt -o combos.txt --leet max:2
+
+    # CLI mode - a different wordlist for every position
+    passcomb -o combos.txt --slot names.txt --slot years.txt --slot suffixes.txt
+
+    # CLI mode - a symbol in any gap of 4-word combinations (a!bcd, ab!cd, abc!d)
+    passcomb -i passwords.txt -o combos.txt -c 4 -s '!' -p between-all
+
+    # CLI mode - !pass123!, #a#b: up to two symbols per candidate
+    passcomb -i passwords.txt -o combos.txt -c 2 -s '!#' -p start,between,end --max-symbols 2
+
+    # CLI mode - multi-character prefixes and suffixes
+    passcomb -i passwords.txt -o combos.txt -c 2 --prefixes '123,!!' --suffix-file suffixes.txt
+
+    # CLI mode - john_smith_1990, john.smith-1990, johnsmith1990, ...
+    passcomb -o combos.txt --slot first.txt --slot last.txt --slot years.txt --separators '-_.'
+
+    # CLI mode - name, separator, capitalized name, year and a symbol
+    passcomb -i names.txt -o combos.txt -s '!@#' --template '{w}{sep}{w|cap}{year}{sym}'
+
+    # CLI mode - a word followed by two digits and a symbol (word42!)
+    passcomb -i passwords.txt -o combos.txt -c 2 --mask-slot 2='?d?d?s'
+
+    # CLI mode - custom charsets in a mask slot and as symbols
+    passcomb -i passwords.txt -o combos.txt -c 2 -1 'aeiou' -2 '!@#$' --mask-slot 2='?1?d' -s '?2' -p start
+
+    # CLI mode - name + year and PIN suffixes without listing the numbers in a file
+    passcomb -i names.txt -o combos.txt -c 2 --range-slot 2=1950-2030 --suffix-range 0000-9999
+
+    # CLI mode - name + birthdate in common formats
+    passcomb -i names.txt -o combos.txt -c 2 --date-slot 2=1970..2005:DDMMYYYY,DDMM,DD.MM.YY
+
+    # CLI mode - word + keyboard walk (john1qaz2wsx, johnqwerty, john!QAZ)
+    passcomb -i names.txt -o combos.txt -c 2 --walk-slot 2=4-8:repeat,shift=both
+
     # CLI mode - large output with file splitting
     passcomb -i passwords.txt -o combos.txt -c 4 -m 50
 
+    # CLI mode - candidates 1000000..1999999 of the keyspace
+    passcomb -i passwords.txt -o part2.txt -c 3 --skip 1000000 --limit 1000000
+
+    # CLI mode - third of eight machines
+    passcomb -i passwords.txt -o shard3.txt -c 3 -s '!@#' -p start,end --shard 3/8
+
+COMBINATION MODES:
+    product       Every word in every slot, all orderings (aa, ab, ba, bb)
+    permutation   No word is used twice in one combination (ab, ba), not even
+                  as one of its variants
+    combination   Each unordered set of words once, in input order (ab)
+
+KEYBOARD LAYOUTS:
+    --layout adds every word as typed with the wrong keyboard layout active, in both
+    directions: with --layout ru, пароль also gives gfhjkm (typed on QWERTY keys) and
+    password also gives зфыыцщкв. A direction applies only to words that can be typed
+    entirely on its source layout, so mixed words are left alone. Layouts: ru
+    (ЙЦУКЕН), ua (Ukrainian), by (Belarusian), de (QWERTZ), fr (AZERTY). Case and
+    leet variants are added for the transposed words as well.
+
+TRANSLITERATION:
+    --translit adds every Cyrillic or Greek word spelled in Latin letters, before
+    case and leet variants are added:
+        gost      GOST R 52535.1 (passports)       жук -> zhuk, Юля -> Iulia
+        iso9      ISO 9 with diacritics            жук -> žuk, Юля -> Ûlâ
+        informal  every common spelling            жук -> zhuk, juk; Юля -> Yulya,
+                  (zh/j, kh/h/x, ya/ja/ia, ...)    Yulja, Yulia, Julya, ... Ulia
+        greek     ELOT 743 for Greek words         Αθήνα -> Athina, αυτό -> afto,
+                  (ου ou, αυ/ευ av/af, γγ ng)      Ευάγγελος -> Evangelos
+    Russian, Ukrainian and Belarusian letters are covered. Characters a scheme does
+    not know are kept, words without any are left alone, and an upper case letter
+    before another (ЖУК) is spelled in upper case (ZHUK). Transpositions from
+    --layout are transliterated too (ghbdtn -> привет -> privet). The run summary
+    reports the growth of the input wordlist and of every --slot wordlist as
+    "with variants".
+
+CASE VARIANTS:
+    --case adds variants of every word before the words are combined, so each part
+    of a combination varies on its own (JohnSmith, johnSMITH, JOHNsmith):
+        lower     john          upper     JOHN
+        cap       John          invcap    jOHN
+        toggle    every letter's case swapped (JoHn -> jOhN)
+        perm:N    every upper/lower combination of words with at most N letters
+    The original word is always kept, and a variant equal to one already produced
+    (digits, words that are already lower case) is counted and generated once. In
+    permutation and combination mode a word is never combined with its own variants
+    (case, leet, layout or transliteration): perm:2 on a and b gives aB, never aA.
+
+LEETSPEAK:
+    --leet adds variants of every word (and of its case variants) with characters
+    replaced by their leet substitutes:
+        all       every substitutable character replaced      (pass -> p4$$, p@55, ...)
+        subsets   any combination of substitutions            (pass -> p4ss, pa5s, ...)
+        max:K     any combination of at most K substitutions  (max:1: p4ss, pa5s, ...)
+    The built-in table is a=4,@  b=8  e=3  g=9  i=1,!  l=1  o=0  s=5,$  t=7  z=2,
+    which upper case letters share. A --leet-table file lists one substitution per
+    line, e.g. "a=4"; a character may appear on several lines, and lines starting
+    with '#' are comments. subsets grows exponentially with word length, so prefer
+    max:K for long words. Identical variants are generated once, and the number of
+    words per input word is shown before generation starts.
+
+RULES:
+    --rules applies every rule of a hashcat rule file (one rule per line, '#' starts
+    a comment) to every candidate, so the count is multiplied by the number of
+    rules. Supported functions:
+        :  nothing          l  lower            u  upper           c  capitalize
+        C  invert capital   t  toggle case      TN toggle at N     r  reverse
+        d  duplicate        f  reflect          {  rotate left     }  rotate right
+        $X append X         ^X prepend X        [  delete first    ]  delete last
+        DN delete at N      iNX insert X at N   oNX overwrite at N sXY replace X by Y
+        @X purge X          xNM extract M at N  'N truncate at N
+    Positions are 0-9 and A-Z (10-35); functions beyond the end of a word leave it
+    unchanged. Without ':' in the file the unmodified candidate is not generated.
+    With --drop-rejected, empty outputs, outputs over 256 bytes and outputs another
+    rule already produced for the same candidate are skipped; the count shown is
+    then an upper bound. With --rules-per-word the rules replace every word (and
+    its case and leet variants) by their outputs before combining, and the counts
+    are exact.
+
+SLOTS:
+    By default every position of a combination draws from the input file. With
+    --slot each position gets its own wordlist, e.g. names, then years, then
+    suffixes, and the keyspace is the product of the slot sizes. Positions past
+    the last --slot (with a larger -c or --max-count) use the input file.
+
+    A slot can also be a hashcat-style mask: --slot 'mask:?d?d' or --mask-slot K=MASK,
+    which replaces slot K of the --slot list or of the -c input slots; K must not
+    exceed the combination size. Built-in
+    charsets: ?l a-z, ?u A-Z, ?d 0-9, ?h 0-9a-f, ?H 0-9A-F, ?s symbols and space,
+    ?a ?l?u?d?s, ?b bytes 0x00-0xff; '??' is a literal '?', other characters are
+    copied as is. Each ? multiplies the slot size by the size of its charset.
+
+    A slot can also be a number range: --slot 'range:1950-2030' or --range-slot
+    K=N-M. A lower bound with leading zeros pads every number to its width, so
+    0000-9999 yields 0000, 0001, ..., 9999. Numbers are generated on the fly.
+
+    Date slots (--slot 'date:SPEC' or --date-slot K=SPEC) hold every day of a range
+    in one or more formats. SPEC is FROM..TO[:FORMAT,...] where FROM and TO are
+    YYYY-MM-DD dates or years, e.g. 1990-06-01..1990-08-31 or 1970..2005. Formats
+    are built from YYYY, YY, MM, DD and the unpadded M and D; other characters are
+    copied (DD.MM.YYYY, D/M/YY), except letters: DDMMY or DDxMM is an error.
+    Default formats: DDMMYYYY, DDMMYY, DDMM, MMDDYYYY, YYYYMMDD. A date string
+    produced twice (e.g. DDMM for every year) is kept once.
+
+    Walk slots (--slot 'walk:SPEC' or --walk-slot K=SPEC) hold keyboard walks on a
+    US QWERTY keyboard, such as qwerty, zxcvbn, 1qaz or 0okm. SPEC is MIN-MAX (or a
+    single length, 2 to 16 keys) followed by ':' and comma-separated options:
+        right, left, up, down, down-left, up-right
+                       directions a walk may move in (all by default); "down" from
+                       1 is q, then a, then z
+        turns=N        direction changes within a walk, 0 to 3 [default: 0]
+        repeat         also parallel strokes: 1qaz2wsx, 1q2w3e4r, qwerasdf
+        shift=MODE     no, only (!QAZ) or both (1qaz and !QAZ) [default: no]
+    A walk never visits a key twice, and walks are counted like any other slot.
+
+CUSTOM CHARSETS:
+    -1 to -4 define the charsets ?1 to ?4. As in hashcat, a charset is a set of bytes:
+    a character of several bytes, such as a UTF-8 'ä', adds each of its bytes. A
+    definition lists characters and byte ranges (a-z, \x80-\xff), may include
+    built-in charsets (?l?d?b) and lower numbered custom charsets, and supports the
+    escapes \- \? \\ \t and \xHH (the byte HH). A value ending in .hcchr is read
+    from that file, whose bytes are taken literally in any encoding. Masks (?1), --symbols ('?1',
+    where '?' not followed by a charset is a literal '?') and template placeholders
+    ({mask:?1?d}, {sym:?2}) can use them. Undefined charsets are reported as errors.
+
+TEMPLATES:
+    A template describes the shape of every candidate. Text is copied as is ('{{'
+    and '}}' for literal braces) and each placeholder is replaced by one of its words:
+        {w}            a word from the input file
+        {w:file}       a word from another wordlist
+        {mask:MASK}    a hashcat-style mask (see SLOTS)
+        {sym}          one of the --symbols
+        {sym:chars}    one of the given characters
+        {pre}, {suf}   one of the prefixes or suffixes
+        {sep}          nothing or one of --separators (- _ . by default)
+        {N-M}          a number from N to M, zero padded if N is (e.g. {00-99})
+        {year}         a year from 1950 to 2030
+        {date:SPEC}    a date, e.g. {date:1970..2005:DDMMYY} (see SLOTS)
+        {walk:SPEC}    a keyboard walk, e.g. {walk:4-8:repeat} (see SLOTS)
+    Transforms follow a '|': {w|cap}, {w|lower}, {w|upper}. In permutation and
+    combination mode the {w} placeholders never share a word. The other options
+    are translated into templates, e.g. -c 2 -s '!' -p end is '{w}{w}' then '{w}{w}{sym}'.
+    Symbol templates keep the flags' order: for every base candidate each symbol is
+    tried at each position before the next base (!aa aa! @aa aa@ !ab ...).
+
+AFFIXES:
+    Prefixes and suffixes are whole strings such as '123', '@2024' or '_admin'. For
+    every size, after the base and symbol candidates, each prefix is put before every
+    combination and then each suffix after it, so the keyspace grows by
+    (prefixes + suffixes) x combinations. Commas cannot be used in --prefixes and
+    --suffixes; put such affixes in a file.
+
+SEPARATORS:
+    --separators puts a separator at every gap between words: with '-_' and three
+    words each of the two gaps is nothing, '-' or '_' (9 variants). With
+    --same-separator all gaps of a candidate use the same one (3 variants).
+    Symbols at 'between' go after the last separator.
+
 SYMBOL POSITIONS:
-    start     Add symbols at the beginning of combinations
-    end       Add symbols at the end of combinations  
-    between   Add symbols between password parts
+    start         Add symbols at the beginning of combinations
+    end           Add symbols at the end of combinations
+    between       Add symbols before the last password part
+    between-all   Add symbols at every gap between password parts, one gap at a time
+    gapN          Add symbols between part N and part N+1 (gap1, gap2, ...); ignored
+                  for combinations with fewer than N+1 parts
+
+    Each candidate gets one symbol at one position unless --max-symbols N is given:
+    then every set of up to N distinct positions gets a symbol at each of them, any
+    symbol at any position. With 3 symbols at start,end and N=2 every combination
+    yields 3 + 3 + 3x3 symbol candidates.
+
+PARTIAL RUNS:
+    --skip and --limit select an exact, contiguous slice of the keyspace, so a job
+    can be split into non-overlapping ranges (e.g. --skip 0 --limit N, then
+    --skip N --limit N). The order of candidates is always the same.
+
+    --shard K/N splits the selected range into N near-equal, disjoint parts and
+    generates the K-th one. Running K=1..N with the same options on N machines
+    covers every candidate exactly once.
+
+CHECKPOINTS:
+    Progress is saved to the checkpoint file every 30 seconds and when the run is
+    interrupted with Ctrl-C, after the output written so far is synced to disk.
+    'passcomb --resume <checkpoint>' restores all options from the checkpoint and
+    continues writing exactly where the run stopped. The checkpoint is removed once
+    generation completes.
 
 FILE SIZE LIMITING:
     When the output exceeds the max file size, new files are created with numeric suffixes:
@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseCharset expands a custom charset definition into its bytes. Like a
+// hashcat charset, a custom charset is a set of bytes: a character of several
+// bytes adds each of them. A definition lists characters and byte ranges such
+// as a-z or \x80-\xff, may include built-in charsets (?l ?u ?d ?h ?H ?s ?a ?b)
+// and custom charsets defined before it (?1 to ?4), and escapes \- \? \\ \t
+// and \xHH. Repeated bytes are kept once.
+func ParseCharset(def string, custom [4]string) (string, error) {
+	var chars []byte
+	add := func(b byte) {
+		if !slices.Contains(chars, b) {
+			chars = append(chars, b)
+		}
+	}
+
+	// Read the next byte, resolving escapes
+	next := func(s string) (b byte, size int, err error) {
+		if s[0] != '\\' {
+			return s[0], 1, nil
+		}
+		if len(s) == 1 {
+			return 0, 0, fmt.Errorf("charset %q ends with '\\'", def)
+		}
+		switch s[1] {
+		case 't':
+			return '\t', 2, nil
+		case 'x':
+			if len(s) < 4 {
+				return 0, 0, fmt.Errorf("incomplete \\x escape in charset %q", def)
+			}
+			n, err := strconv.ParseUint(s[2:4], 16, 8)
+			if err != nil {
+				return 0, 0, fmt.Errorf("invalid \\x escape %q in charset %q", s[:4], def)
+			}
+			return byte(n), 4, nil
+		default:
+			return s[1], 2, nil
+		}
+	}
+
+	for s := def; s != ""; {
+		if s[0] == '?' && len(s) > 1 && s[1] != '?' {
+			chars, err := charsetRef(s[1], custom)
+			if err != nil {
+				return "", fmt.Errorf("charset %q: %w", def, err)
+			}
+			for _, c := range chars {
+				add(c[0])
+			}
+			s = s[2:]
+			continue
+		}
+		if strings.HasPrefix(s, "??") {
+			add('?')
+			s = s[2:]
+			continue
+		}
+
+		from := s
+		lo, size, err := next(s)
+		if err != nil {
+			return "", err
+		}
+		s = s[size:]
+
+		// A '-' between two characters is a range
+		if len(s) > 1 && s[0] == '-' {
+			hi, size, err := next(s[1:])
+			if err != nil {
+				return "", err
+			}
+			text := from[:len(from)-len(s)+1+size]
+			if from[0] >= utf8.RuneSelf || s[1] >= utf8.RuneSelf {
+				return "", fmt.Errorf("invalid range %s in charset %q: ranges are of bytes, use \\xHH-\\xHH", text, def)
+			}
+			if hi < lo {
+				return "", fmt.Errorf("invalid range %s in charset %q", text, def)
+			}
+			for b := int(lo); b <= int(hi); b++ {
+				add(byte(b))
+			}
+			s = s[1+size:]
+			continue
+		}
+		add(lo)
+	}
+
+	if len(chars) == 0 {
+		return "", fmt.Errorf("empty charset")
+	}
+	return string(chars), nil
+}
+
+// ReadCharsetFile reads a hashcat .hcchr file, whose content is the list of
+// bytes of the charset, in any encoding.
+func ReadCharsetFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read charset file: %w", err)
+	}
+
+	var chars []byte
+	for _, b := range bytes.TrimRight(data, "\r\n") {
+		if !slices.Contains(chars, b) {
+			chars = append(chars, b)
+		}
+	}
+	if len(chars) == 0 {
+		return "", fmt.Errorf("charset file %s is empty", path)
+	}
+	return string(chars), nil
+}
+
+// charsetRef returns the characters of the charset a '?' is followed by:
+// a built-in letter or a custom charset digit.
+func charsetRef(c byte, custom [4]string) ([]string, error) {
+	if c >= '1' && c <= '4' {
+		chars := custom[c-'1']
+		if chars == "" {
+			return nil, fmt.Errorf("custom charset ?%c is not defined", c)
+		}
+		return splitBytes(chars), nil
+	}
+	if chars, ok := builtinCharsets[c]; ok {
+		return splitBytes(chars), nil
+	}
+	return nil, fmt.Errorf("unknown charset ?%c (valid: ?l ?u ?d ?h ?H ?s ?a ?b ?1-?4)", c)
+}
+
+// splitBytes returns the bytes of a charset, one word each.
+func splitBytes(chars string) []string {
+	words := make([]string, len(chars))
+	for i := range len(chars) {
+		words[i] = chars[i : i+1]
+	}
+	return words
+}
+
+// expandSymbols returns the symbols of a symbol list in which ?-references to
+// charsets (e.g. ?1, ?d) are expanded; other characters, including a '?' not
+// followed by a charset, stand for themselves.
+func expandSymbols(s string, custom [4]string) ([]string, error) {
+	var symbols []string
+	for len(s) > 0 {
+		if s[0] == '?' && len(s) > 1 && isCharsetRef(s[1]) {
+			chars, err := charsetRef(s[1], custom)
+			if err != nil {
+				return nil, err
+			}
+			symbols = append(symbols, chars...)
+			s = s[2:]
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(s)
+		symbols = append(symbols, s[:size])
+		s = s[size:]
+	}
+	return symbols, nil
+}
+
+func isCharsetRef(c byte) bool {
+	_, ok := builtinCharsets[c]
+	return ok || c >= '1' && c <= '4'
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseCharset(t *testing.T) {
+	custom := [4]string{"aeiou"}
+
+	tests := []struct {
+		def      string
+		expected string
+		wantErr  bool
+	}{
+		{def: "aeiou", expected: "aeiou"},
+		{def: "a-f0-3", expected: "abcdef0123"},
+		{def: "-a-c-", expected: "-abc"},
+		{def: `\-\?\\x`, expected: `-?\x`},
+		{def: `\x41-\x43\t`, expected: "ABC\t"},
+		{def: `\xe4\xfe-\xff`, expected: "\xe4\xfe\xff"},
+		{def: "äö", expected: "\xc3\xa4\xb6"},
+		{def: "?b", expected: allBytes()},
+		{def: "?d?1", expected: "0123456789aeiou"},
+		{def: "??!aa", expected: "?!a"},
+		{def: "z-a", wantErr: true},
+		{def: "?2", wantErr: true},
+		{def: "?x", wantErr: true},
+		{def: "а-я", wantErr: true},
+		{def: `\x4`, wantErr: true},
+		{def: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			chars, err := ParseCharset(tt.def, custom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCharset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && chars != tt.expected {
+				t.Errorf("ParseCharset() = %q, want %q", chars, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReadCharsetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vowels.hcchr")
+	if err := os.WriteFile(path, []byte("aeiouäa-\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	chars, err := ReadCharsetFile(path)
+	if err != nil {
+		t.Fatalf("ReadCharsetFile() error: %v", err)
+	}
+	if chars != "aeiou\xc3\xa4-" {
+		t.Errorf("ReadCharsetFile() = %q, want %q", chars, "aeiou\xc3\xa4-")
+	}
+
+	// Latin-1 umlauts are bytes, not UTF-8
+	if err := os.WriteFile(path, []byte("\xe4\xf6\xfc\xe4\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	chars, err = ReadCharsetFile(path)
+	if err != nil {
+		t.Fatalf("ReadCharsetFile() error: %v", err)
+	}
+	if chars != "\xe4\xf6\xfc" {
+		t.Errorf("ReadCharsetFile() = %q, want %q", chars, "\xe4\xf6\xfc")
+	}
+}
+
+func TestCustomCharsets(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			Template:     "{mask:?1?d}{sym}",
+			ExtraSymbols: []rune("?2?"),
+			Charsets:     [4]string{"xy", "!@"},
+		},
+	}
+
+	expected := []string{"x0!", "x0@", "x0?", "x1!"}
+	if result := collect(g); len(result) != 60 || !slices.Equal(result[:4], expected) {
+		t.Errorf("got %d candidates starting with %v, want 60 starting with %v", len(result), result[:min(4, len(result))], expected)
+	}
+
+	// A custom charset is a set of bytes, as are masks
+	parts, err := parseMask("?1", &[4]string{"\xe4\xf6\xfc"})
+	if err != nil || maskSize(parts) != 3 {
+		t.Errorf("parseMask() = %d words, %v, want 3", maskSize(parts), err)
+	}
+
+	g.config.Charsets[0] = ""
+	if err := g.Validate(); err == nil {
+		t.Errorf("expected error for undefined custom charset ?1")
+	}
+}
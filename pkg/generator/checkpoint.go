@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const DefaultCheckpointInterval = 30 * time.Second
+
+// Checkpoint records how far a generation got, so an interrupted run can
+// continue writing exactly where it stopped.
+type Checkpoint struct {
+	Config Config `json:"config"`
+
+	Keyspace int64 `json:"keyspace"`
+	Start    int64 `json:"start"`
+	End      int64 `json:"end"`
+
+	// Odometer state of the next candidate to generate
+	Index   int64 `json:"index"`
+	Segment int   `json:"segment"`
+	Indices []int `json:"indices"`
+
+	// Output state; everything past FileOffset in file FileNumber is discarded on resume
+	FileNumber int   `json:"file_number"`
+	FileOffset int64 `json:"file_offset"`
+}
+
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	return &cp, nil
+}
+
+// Save writes the checkpoint atomically, so a crash while saving leaves the
+// previous checkpoint intact.
+func (cp *Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (g *Generator) newCheckpoint(it *Iterator, writer *splitWriter) *Checkpoint {
+	keyspace, _ := g.KeyspaceSize()
+	start, end, _ := g.Range()
+
+	return &Checkpoint{
+		Config:     g.config,
+		Keyspace:   keyspace,
+		Start:      start,
+		End:        end,
+		Index:      it.index,
+		Segment:    it.segment,
+		Indices:    slices.Clone(it.indices),
+		FileNumber: writer.FileNumber(),
+		FileOffset: writer.Size(),
+	}
+}
+
+// checkCheckpoint verifies that cp was written by a run over the same keyspace
+// and output as the generator's current configuration.
+func (g *Generator) checkCheckpoint(cp *Checkpoint) error {
+	keyspace, err := g.KeyspaceSize()
+	if err != nil {
+		return err
+	}
+	start, end, _ := g.Range()
+
+	if cp.Keyspace != keyspace || cp.Start != start || cp.End != end {
+		return fmt.Errorf("checkpoint does not match the current keyspace")
+	}
+	if cp.Config.OutputFile != g.config.OutputFile {
+		return fmt.Errorf("checkpoint was written for output %s", cp.Config.OutputFile)
+	}
+	if cp.Index < start || cp.Index > end {
+		return fmt.Errorf("checkpoint index %d outside of range %d-%d", cp.Index, start, end)
+	}
+	if cp.FileNumber < 1 || cp.FileOffset < 0 {
+		return fmt.Errorf("checkpoint has invalid output position")
+	}
+
+	it := g.iteratorAt(cp.Index)
+	if cp.Index < end && (cp.Segment != it.segment || !slices.Equal(cp.Indices, it.indices)) {
+		return fmt.Errorf("checkpoint does not match the current keyspace")
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func drain(progressChan chan ProgressInfo, fn func(ProgressInfo)) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for progress := range progressChan {
+			fn(progress)
+		}
+	}()
+	return done
+}
+
+func run(ctx context.Context, g *Generator, fn func(ProgressInfo)) error {
+	progressChan := make(chan ProgressInfo)
+	done := drain(progressChan, fn)
+	err := g.GenerateCombinationsContext(ctx, progressChan)
+	close(progressChan)
+	<-done
+	return err
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+
+	var passwords []string
+	for i := range 100 {
+		passwords = append(passwords, fmt.Sprintf("w%d", i))
+	}
+
+	config := Config{
+		OutputFile:      filepath.Join(dir, "out.txt"),
+		CombinationSize: 2,
+		ExtraSymbols:    []rune{'!'},
+		SymbolPositions: []SymbolPosition{PositionEnd},
+		MaxFileSizeMB:   1,
+		CheckpointFile:  filepath.Join(dir, "out.checkpoint"),
+	}
+
+	// Interrupt the first run part way through
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &Generator{config: config, passwords: passwords}
+	err := run(ctx, g, func(progress ProgressInfo) {
+		if progress.Generated == 5000 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateCombinationsContext() error = %v, want context.Canceled", err)
+	}
+
+	cp, err := LoadCheckpoint(config.CheckpointFile)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error: %v", err)
+	}
+	if cp.Index != 2*checkpointCheckEvery {
+		t.Errorf("checkpoint index = %d, want %d", cp.Index, 2*checkpointCheckEvery)
+	}
+
+	// Simulate a crash that left a partial line behind
+	file, err := os.OpenFile(config.OutputFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("w1w")
+	file.Close()
+
+	config.Resume = true
+	g = &Generator{config: config, passwords: passwords}
+	if err := run(context.Background(), g, func(ProgressInfo) {}); err != nil {
+		t.Fatalf("resumed GenerateCombinationsContext() error: %v", err)
+	}
+
+	if _, err := os.Stat(config.CheckpointFile); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after completion")
+	}
+
+	data, err := os.ReadFile(config.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join(collect(g), "\n") + "\n"
+	if string(data) != expected {
+		t.Errorf("resumed output differs from a full run (%d bytes, want %d)", len(data), len(expected))
+	}
+}
+
+func TestResumeRejectsDifferentKeyspace(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{
+		OutputFile:      filepath.Join(dir, "out.txt"),
+		CombinationSize: 2,
+		CheckpointFile:  filepath.Join(dir, "out.checkpoint"),
+		Resume:          true,
+	}
+
+	g := &Generator{config: config, passwords: []string{"a", "b"}}
+	cp := &Checkpoint{Config: config, Keyspace: 9, End: 9, FileNumber: 1}
+	if err := cp.Save(config.CheckpointFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := run(context.Background(), g, func(ProgressInfo) {}); err == nil {
+		t.Errorf("expected error resuming a checkpoint with a different keyspace")
+	}
+}
+
+func TestCancelWhileDroppingEveryOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	var passwords []string
+	for i := range 100 {
+		passwords = append(passwords, fmt.Sprintf("w%d", i))
+	}
+
+	// Truncating to nothing rejects every candidate, so no line is written
+	config := Config{
+		InputFile:       writeWordlist(t, passwords...),
+		OutputFile:      filepath.Join(dir, "out.txt"),
+		CombinationSize: 2,
+		RuleFile:        filepath.Join(dir, "empty.rule"),
+		DropRejected:    true,
+		CheckpointFile:  filepath.Join(dir, "out.checkpoint"),
+	}
+	if err := os.WriteFile(config.RuleFile, []byte("'0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(config)
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := run(ctx, g, func(ProgressInfo) {}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GenerateCombinationsContext() error = %v, want context.Canceled", err)
+	}
+
+	cp, err := LoadCheckpoint(config.CheckpointFile)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error: %v", err)
+	}
+	if cp.Index != checkpointCheckEvery || cp.FileOffset != 0 {
+		t.Errorf("checkpoint at index %d, offset %d, want %d, 0", cp.Index, cp.FileOffset, checkpointCheckEvery)
+	}
+}
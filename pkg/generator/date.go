@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDateFormats are used by date slots that do not list formats.
+var DefaultDateFormats = []string{"DDMMYYYY", "DDMMYY", "DDMM", "MMDDYYYY", "YYYYMMDD"}
+
+// dateTokens are the fields of a date format, longest first. Other characters
+// of a format are copied as is.
+var dateTokens = []string{"YYYY", "YY", "MM", "DD", "M", "D"}
+
+// dateSpec is a parsed date slot: every day from from to to, in each format.
+type dateSpec struct {
+	from, to time.Time
+	formats  []string
+}
+
+// parseDateSpec parses FROM..TO[:FORMAT,...], where FROM and TO are dates
+// (2006-01-02) or years (2006 covers the whole year), e.g.
+// "1970..2005:DDMMYYYY,DD.MM.YY".
+func parseDateSpec(s string) (dateSpec, error) {
+	bounds, formats, _ := strings.Cut(s, ":")
+	fromStr, toStr, ok := strings.Cut(bounds, "..")
+	if !ok {
+		return dateSpec{}, fmt.Errorf("invalid date range %q (expected FROM..TO, e.g. 1970..2005 or 1990-01-01..1999-12-31)", bounds)
+	}
+
+	from, err := parseDateBound(fromStr, false)
+	if err != nil {
+		return dateSpec{}, err
+	}
+	to, err := parseDateBound(toStr, true)
+	if err != nil {
+		return dateSpec{}, err
+	}
+	if to.Before(from) {
+		return dateSpec{}, fmt.Errorf("invalid date range %q (end before start)", bounds)
+	}
+
+	spec := dateSpec{from: from, to: to, formats: DefaultDateFormats}
+	if formats != "" {
+		spec.formats = strings.Split(formats, ",")
+		for _, format := range spec.formats {
+			if err := checkDateFormat(format); err != nil {
+				return dateSpec{}, err
+			}
+		}
+	}
+	return spec, nil
+}
+
+// checkDateFormat rejects a format without date fields or with a run of Y,
+// M or D letters that is not a field (DDMMY, MMM), as well as letters used as
+// separators.
+func checkDateFormat(format string) error {
+	if !strings.ContainsAny(format, "YMD") {
+		return fmt.Errorf("date format %q has no date fields (use YYYY, YY, MM, DD, M, D)", format)
+	}
+
+	for i := 0; i < len(format); {
+		c, n := format[i], 1
+		for i+n < len(format) && format[i+n] == c {
+			n++
+		}
+		field := format[i : i+n]
+
+		switch {
+		case c == 'Y' && n != 2 && n != 4, (c == 'M' || c == 'D') && n > 2:
+			return fmt.Errorf("invalid field %q in date format %q (use YYYY, YY, MM, DD, M, D)", field, format)
+		case c != 'Y' && c != 'M' && c != 'D' && ('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'):
+			return fmt.Errorf("invalid separator %q in date format %q (fields are YYYY, YY, MM, DD, M, D)", field, format)
+		}
+		i += n
+	}
+	return nil
+}
+
+// parseDateBound parses a date or a year; a year stands for its first or,
+// with end set, its last day.
+func parseDateBound(s string, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if year, err := strconv.Atoi(s); err == nil && len(s) == 4 {
+		if end {
+			return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC), nil
+		}
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY or YYYY-MM-DD)", s)
+	}
+	return t, nil
+}
+
+// words returns every date of the range in every format, format by format,
+// keeping only the first occurrence of a string.
+func (d dateSpec) words() []string {
+	var words []string
+	seen := make(map[string]bool)
+
+	for _, format := range d.formats {
+		for t := d.from; !t.After(d.to); t = t.AddDate(0, 0, 1) {
+			word := formatDate(t, format)
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
+
+func formatDate(t time.Time, format string) string {
+	var b strings.Builder
+	for format != "" {
+		token := ""
+		for _, candidate := range dateTokens {
+			if strings.HasPrefix(format, candidate) {
+				token = candidate
+				break
+			}
+		}
+
+		switch token {
+		case "YYYY":
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case "YY":
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case "MM":
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case "DD":
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case "M":
+			fmt.Fprintf(&b, "%d", int(t.Month()))
+		case "D":
+			fmt.Fprintf(&b, "%d", t.Day())
+		default:
+			token = format[:1]
+			b.WriteString(token)
+		}
+		format = format[len(token):]
+	}
+	return b.String()
+}
+
+// dateList returns the words of a date slot, built once per spec.
+func (g *Generator) dateList(spec string) *wordList {
+	if list, ok := g.dates[spec]; ok {
+		return list
+	}
+
+	d, err := parseDateSpec(spec)
+	if err != nil {
+		return newWordList(nil)
+	}
+	if g.dates == nil {
+		g.dates = make(map[string]*wordList)
+	}
+	g.dates[spec] = newWordList(d.words())
+	return g.dates[spec]
+}
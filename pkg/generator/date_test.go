@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDateSlot(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected []string
+		count    int // Checked instead of expected when set
+		wantErr  bool
+	}{
+		{
+			spec:     "2024-02-28..2024-03-01:DDMMYYYY,DD.MM.YY",
+			expected: []string{"28022024", "29022024", "01032024", "28.02.24", "29.02.24", "01.03.24"},
+		},
+		{
+			// Days repeat across years and the repeated format adds nothing
+			spec:  "2023..2024:DDMM,D.M,D.M",
+			count: 2 * 366,
+		},
+		{
+			spec:     "1999-12-31..2000-01-01:YYMMDD,MMDDYY,YYYYMD",
+			expected: []string{"991231", "000101", "123199", "010100", "19991231", "200011"},
+		},
+		{spec: "2024..2023", wantErr: true},
+		{spec: "2024", wantErr: true},
+		{spec: "2024-13-01..2025", wantErr: true},
+		{spec: "2023..2024:day", wantErr: true},
+		{spec: "2023..2024:DDMMY", wantErr: true},
+		{spec: "2023..2024:DDMMYYY", wantErr: true},
+		{spec: "2023..2024:MMMDD", wantErr: true},
+		{spec: "2023..2024:DDxMM", wantErr: true},
+		{spec: "2023..2024:ddmmyyyy", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			d, err := parseDateSpec(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDateSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			words := d.words()
+			if tt.count > 0 {
+				if len(words) != tt.count || len(slices.Compact(slices.Sorted(slices.Values(words)))) != tt.count {
+					t.Errorf("got %d words, want %d distinct", len(words), tt.count)
+				}
+			} else if !slices.Equal(words, tt.expected) {
+				t.Errorf("words = %v, want %v", words, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDateSlotCandidates(t *testing.T) {
+	spec, err := ParseSlotSpec("date:2024-01-30..2024-02-01:DDMM")
+	if err != nil {
+		t.Fatalf("ParseSlotSpec() error: %v", err)
+	}
+
+	g := &Generator{
+		config:    Config{Slots: []SlotSpec{{}, spec}},
+		passwords: []string{"ann", "bob"},
+	}
+
+	expected := []string{"ann3001", "ann3101", "ann0102", "bob3001", "bob3101", "bob0102"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+	if indices := g.IndexOf("bob3101"); !slices.Equal(indices, []int64{4}) {
+		t.Errorf("IndexOf(%q) = %v, want [4]", "bob3101", indices)
+	}
+}
@@ -1,35 +1,70 @@
 package generator
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
-	"path/filepath"
-	"strings"
+	"time"
 )
 
 type Config struct {
-	InputFile       string
-	OutputFile      string
-	CombinationSize int
-	ExtraSymbols    []rune
-	SymbolPositions []SymbolPosition
-	MaxFileSizeMB   int
+	InputFile          string
+	OutputFile         string
+	CombinationSize    int
+	MinCombinationSize int // Smallest size generated, CombinationSize if zero
+	MaxCombinationSize int // Largest size generated, MinCombinationSize if zero
+	Mode               Mode
+	Layouts            []string          // Keyboard layouts every word is transposed to and from QWERTY, see KeyboardLayouts
+	Transliterations   []Transliteration // Latin variants added for Cyrillic and Greek words
+	CaseMutations      []CaseMutation    // Case variants added for every word
+	Leet               LeetMode          // Leetspeak variants added for every word
+	LeetTable          string            // Leet substitution table file, DefaultLeetTable if empty
+	RuleFile           string            // Hashcat rules applied to every candidate, or to every word with RulesPerWord
+	RulesPerWord       bool              // Apply RuleFile to every word before combining instead of to candidates
+	DropRejected       bool              // Skip empty, over-long and repeated rule and mutator outputs of a candidate
+	WordMutators       []Mutator         `json:"-"` // Applied in order to every word after case, leet and word rules
+	CandidateMutators  []Mutator         `json:"-"` // Applied in order to every candidate after candidate rules
+	Template           string            // Candidate template (see Template), replaces sizes, slots and symbol positions when set
+	Slots              []SlotSpec        // Per-position word sources, the input wordlist for unset positions
+	ExtraSymbols       []rune            // May refer to charsets, e.g. ?d or ?1
+	Charsets           [4]string         // Characters of the custom charsets ?1 to ?4, see ParseCharset
+	Prefixes           []string          // Strings prepended to combinations
+	Suffixes           []string          // Strings appended to combinations
+	PrefixFile         string            // Wordlist of additional prefixes
+	SuffixFile         string            // Wordlist of additional suffixes
+	SuffixRanges       []string          // Number ranges appended like suffixes, e.g. 0-99 or 1950-2030
+	Separators         []rune            // Inserted at every gap between words, along with no separator
+	SameSeparator      bool              // Use one separator for all gaps of a candidate instead of one per gap
+	SymbolPositions    []SymbolPosition
+	MaxSymbols         int // Symbols inserted at different positions of one candidate, 1 if zero
+	MaxFileSizeMB      int
+	Skip               int64 // Candidates to skip from the start of the keyspace
+	Limit              int64 // Maximum candidates to generate, 0 means no limit
+	ShardIndex         int   // 1-based shard to generate, used when ShardCount > 0
+	ShardCount         int   // Number of disjoint shards the range is split into
+
+	CheckpointFile     string        // Where progress is periodically saved, empty disables checkpoints
+	CheckpointInterval time.Duration // How often to save, DefaultCheckpointInterval if zero
+	Resume             bool          // Continue from CheckpointFile instead of starting over
 }
 
-type SymbolPosition int
-
-const (
-	PositionNone SymbolPosition = iota
-	PositionStart
-	PositionEnd
-	PositionBetween
-)
+// checkpointCheckEvery is how many keyspace indices are enumerated between
+// checks for cancellation and checkpoint deadlines.
+const checkpointCheckEvery = 4096
 
 type Generator struct {
 	config    Config
 	passwords []string
+	input     *wordList
+	files     map[string]*wordList    // Wordlists loaded for slots and templates, by path
+	dates     map[string]*wordList    // Words of date slots, by spec
+	walks     map[string]*wordList    // Words of keyboard walk slots, by spec
+	variants  map[*wordList]*wordList // Wordlists with their mutations, by source list
+	leet      map[rune][]string       // Leet substitutions loaded from LeetTable
+	rules     []Rule                  // Rules loaded from RuleFile
 }
 
 type ProgressInfo struct {
@@ -43,232 +78,344 @@ func NewGenerator(config Config) *Generator {
 	return &Generator{config: config}
 }
 
+// LoadPasswords loads the input wordlist and the wordlists referenced by slots
+// and the template. The input file may be omitted when nothing draws from it.
 func (g *Generator) LoadPasswords() error {
-	file, err := os.Open(g.config.InputFile)
+	g.passwords = nil
+	g.input = nil
+
+	templates, err := g.templates()
 	if err != nil {
-		return fmt.Errorf("failed to open input file: %w", err)
+		return err
 	}
-	defer file.Close()
 
-	var passwords []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		password := strings.TrimSpace(scanner.Text())
-		if password != "" {
-			passwords = append(passwords, password)
+	if g.config.InputFile != "" || usesInput(templates) {
+		passwords, err := readWordlist(g.config.InputFile)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %w", err)
 		}
+		g.passwords = passwords
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading input file: %w", err)
-	}
-
-	g.passwords = passwords
-	return nil
+	return g.loadFiles(templates)
 }
 
-func (g *Generator) CalculateTotalCombinations() int64 {
-	if len(g.passwords) == 0 {
-		return 0
-	}
-
-	baseCombinations := int64(math.Pow(float64(len(g.passwords)), float64(g.config.CombinationSize)))
+// ErrKeyspaceTooLarge is returned when the keyspace does not fit the int64
+// counters used for indexing, progress and checkpoints.
+var ErrKeyspaceTooLarge = errors.New("keyspace too large")
 
-	symbolMultiplier := 1
-	if len(g.config.ExtraSymbols) > 0 && len(g.config.SymbolPositions) > 0 {
-		symbolMultiplier = 1 + len(g.config.ExtraSymbols)*len(g.config.SymbolPositions)
+// CalculateTotalCombinations returns the number of candidates in the slice
+// selected by Skip, Limit and the shard.
+func (g *Generator) CalculateTotalCombinations() (int64, error) {
+	start, end, err := g.Range()
+	if err != nil {
+		return 0, err
 	}
-
-	return baseCombinations * int64(symbolMultiplier)
+	return end - start, nil
 }
 
-func (g *Generator) GenerateCombinations(progressChan chan<- ProgressInfo) error {
-	if len(g.passwords) == 0 {
-		return fmt.Errorf("no passwords loaded")
+// Range returns the half-open interval of keyspace indices the run covers.
+// Skip and Limit select a slice of the keyspace which is then narrowed down
+// to the configured shard.
+func (g *Generator) Range() (start, end int64, err error) {
+	keyspace, err := g.KeyspaceSize()
+	if err != nil {
+		return 0, 0, err
 	}
 
-	totalCombinations := g.CalculateTotalCombinations()
-	if totalCombinations == 0 {
-		return fmt.Errorf("no combinations to generate")
+	start = min(max(g.config.Skip, 0), keyspace)
+	end = keyspace
+	if g.config.Limit > 0 && g.config.Limit < end-start {
+		end = start + g.config.Limit
 	}
 
-	baseDir := filepath.Dir(g.config.OutputFile)
-	baseName := filepath.Base(g.config.OutputFile)
-	ext := filepath.Ext(baseName)
-	nameWithoutExt := strings.TrimSuffix(baseName, ext)
-
-	maxFileSize := int64(g.config.MaxFileSizeMB) * 1024 * 1024
-	if maxFileSize <= 0 {
-		maxFileSize = 100 * 1024 * 1024 // Default 100MB
+	if g.config.ShardCount > 0 {
+		start, end = shardRange(start, end, g.config.ShardIndex, g.config.ShardCount)
 	}
+	return start, end, nil
+}
 
-	var currentFile *os.File
-	var currentFileSize int64
-	fileNumber := 1
-	generated := int64(0)
+// shardRange splits [start, end) into count near-equal parts and returns the
+// 1-based index-th one. The first (end-start)%count shards get one extra candidate.
+func shardRange(start, end int64, index, count int) (int64, int64) {
+	if index < 1 || index > count {
+		return start, start
+	}
 
-	createNewFile := func(num int) (*os.File, error) {
-		if currentFile != nil {
-			currentFile.Close()
-		}
+	total := end - start
+	n := int64(count)
+	i := int64(index - 1)
+	size, rem := total/n, total%n
 
-		var fileName string
-		if num == 1 {
-			fileName = g.config.OutputFile
-		} else {
-			fileName = filepath.Join(baseDir, fmt.Sprintf("%s_%d%s", nameWithoutExt, num, ext))
-		}
+	shardStart := start + i*size + min(i, rem)
+	shardEnd := shardStart + size
+	if i < rem {
+		shardEnd++
+	}
+	return shardStart, shardEnd
+}
 
-		file, err := os.Create(fileName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create output file: %w", err)
-		}
+// KeyspaceSize returns the number of candidates in the whole keyspace,
+// regardless of Skip and Limit, or ErrKeyspaceTooLarge if it overflows int64.
+func (g *Generator) KeyspaceSize() (int64, error) {
+	if err := g.Validate(); err != nil {
+		return 0, err
+	}
 
-		return file, nil
+	keyspace := g.Keyspace()
+	if !keyspace.IsInt64() {
+		return 0, fmt.Errorf("%w: %s candidates exceed the maximum of %d", ErrKeyspaceTooLarge, keyspace, int64(math.MaxInt64))
 	}
+	return keyspace.Int64(), nil
+}
 
-	var err error
-	currentFile, err = createNewFile(fileNumber)
+// Validate reports configuration errors that do not depend on the wordlists:
+// an invalid template or mask, a template placeholder without values, a
+// reference to an undefined custom charset, an unknown keyboard layout or a
+// candidate mutator without variants.
+func (g *Generator) Validate() error {
+	templates, err := g.templates()
 	if err != nil {
 		return err
 	}
-	defer currentFile.Close()
+	if _, err := expandSymbols(string(g.config.ExtraSymbols), g.config.Charsets); err != nil {
+		return fmt.Errorf("invalid symbols: %w", err)
+	}
+	for _, def := range g.config.SuffixRanges {
+		if _, err := parseNumberRange(def); err != nil {
+			return fmt.Errorf("invalid suffix range: %w", err)
+		}
+	}
 
-	writeCombination := func(combination string) error {
-		combinationBytes := []byte(combination + "\n")
+	if err := checkLayouts(g.config.Layouts); err != nil {
+		return err
+	}
+	for i, m := range g.config.CandidateMutators {
+		if m.Variants() < 1 {
+			return fmt.Errorf("candidate mutator %d yields no variants", i+1)
+		}
+	}
 
-		if currentFileSize+int64(len(combinationBytes)) > maxFileSize {
-			fileNumber++
-			currentFile, err = createNewFile(fileNumber)
+	for _, t := range templates {
+		for _, el := range t.elements {
+			switch {
+			case el.name == "mask":
+				_, err = parseMask(el.arg, &g.config.Charsets)
+			case el.name == "range":
+				_, err = parseNumberRange(el.arg)
+			case el.name == "date":
+				_, err = parseDateSpec(el.arg)
+			case el.name == "walk":
+				_, err = parseWalkSpec(el.arg)
+			case el.name == "sym" && el.arg != "":
+				_, err = expandSymbols(el.arg, g.config.Charsets)
+			case el.name == "sym" && len(g.config.ExtraSymbols) == 0:
+				err = fmt.Errorf("template placeholder {sym} needs extra symbols (--symbols) or a list of its own ({sym:CHARS})")
+			case el.name == "pre" && !g.hasPrefixes():
+				err = fmt.Errorf("template placeholder {pre} needs prefixes (--prefixes or --prefix-file)")
+			case el.name == "suf" && !g.hasSuffixes():
+				err = fmt.Errorf("template placeholder {suf} needs suffixes (--suffixes, --suffix-file or --suffix-range)")
+			}
 			if err != nil {
 				return err
 			}
-			currentFileSize = 0
 		}
+	}
+	return nil
+}
 
-		n, err := currentFile.Write(combinationBytes)
-		if err != nil {
-			return fmt.Errorf("failed to write combination: %w", err)
-		}
+// Keyspace returns the exact number of candidates in the whole keyspace. With
+// candidate rules and mutators every base candidate counts once per variant,
+// including the outputs DropRejected skips.
+func (g *Generator) Keyspace() *big.Int {
+	keyspace := new(big.Int)
+	for _, seg := range g.segments() {
+		keyspace.Add(keyspace, seg.count())
+	}
+	return keyspace.Mul(keyspace, g.candidateVariants())
+}
 
-		currentFileSize += int64(n)
-		generated++
+// segments compiles the templates that make up the keyspace, one segment per
+// group.
+func (g *Generator) segments() []*segment {
+	groups, _ := g.templateGroups()
 
-		progressChan <- ProgressInfo{
-			TotalCombinations: totalCombinations,
-			Generated:         generated,
-			CurrentFile:       currentFile.Name(),
-			FileNumber:        fileNumber,
+	segments := make([]*segment, len(groups))
+	for i, group := range groups {
+		compiled := make([]*segment, len(group))
+		for j, t := range group {
+			compiled[j] = g.compile(t)
 		}
-
-		return nil
+		segments[i] = interleave(compiled)
 	}
+	return segments
+}
 
-	// Generate base combinations
-	g.generateBaseCombinations(writeCombination)
+// sizes returns the combination sizes to generate, smallest first.
+func (g *Generator) sizes() []int {
+	minSize := g.config.MinCombinationSize
+	if minSize <= 0 {
+		minSize = g.config.CombinationSize
+	}
+	if minSize <= 0 {
+		minSize = len(g.config.Slots)
+	}
+	maxSize := g.config.MaxCombinationSize
+	if maxSize <= 0 {
+		maxSize = minSize
+	}
 
-	// Generate combinations with extra symbols
-	if len(g.config.ExtraSymbols) > 0 && len(g.config.SymbolPositions) > 0 {
-		g.generateSymbolCombinations(writeCombination)
+	var sizes []int
+	for size := max(minSize, 1); size <= maxSize; size++ {
+		sizes = append(sizes, size)
 	}
+	return sizes
+}
 
-	return nil
+// maxSymbols returns how many symbols a candidate may hold at once.
+func (g *Generator) maxSymbols() int {
+	return max(g.config.MaxSymbols, 1)
+}
+
+func (g *Generator) hasPrefixes() bool {
+	return len(g.config.Prefixes) > 0 || g.config.PrefixFile != ""
 }
 
-func (g *Generator) generateBaseCombinations(writeFunc func(string) error) {
-	indices := make([]int, g.config.CombinationSize)
-	for i := range indices {
-		indices[i] = 0
+func (g *Generator) hasSuffixes() bool {
+	return len(g.config.Suffixes) > 0 || g.config.SuffixFile != "" || len(g.config.SuffixRanges) > 0
+}
+
+func (g *Generator) GenerateCombinations(progressChan chan<- ProgressInfo) error {
+	return g.GenerateCombinationsContext(context.Background(), progressChan)
+}
+
+// GenerateCombinationsContext is like GenerateCombinations but stops when ctx
+// is cancelled, saving a checkpoint first if CheckpointFile is set.
+func (g *Generator) GenerateCombinationsContext(ctx context.Context, progressChan chan<- ProgressInfo) error {
+	if templates, _ := g.templates(); len(g.passwords) == 0 && usesInput(templates) {
+		return fmt.Errorf("no passwords loaded")
 	}
 
-	for {
-		// Build current combination
-		var combination strings.Builder
-		for i := 0; i < g.config.CombinationSize; i++ {
-			combination.WriteString(g.passwords[indices[i]])
-		}
+	totalCombinations, err := g.CalculateTotalCombinations()
+	if err != nil {
+		return err
+	}
+	if totalCombinations == 0 {
+		return fmt.Errorf("no combinations to generate")
+	}
 
-		if err := writeFunc(combination.String()); err != nil {
-			return
-		}
+	maxFileSize := int64(g.config.MaxFileSizeMB) * 1024 * 1024
+	if maxFileSize <= 0 {
+		maxFileSize = 100 * 1024 * 1024 // Default 100MB
+	}
 
-		// Move to next combination
-		carry := 1
-		for i := g.config.CombinationSize - 1; i >= 0 && carry > 0; i-- {
-			indices[i] += carry
-			if indices[i] >= len(g.passwords) {
-				indices[i] = 0
-				carry = 1
-			} else {
-				carry = 0
-			}
+	start, end, _ := g.Range()
+	index := start
+
+	var writer *splitWriter
+	if g.config.Resume {
+		cp, err := LoadCheckpoint(g.config.CheckpointFile)
+		if err != nil {
+			return err
+		}
+		if err := g.checkCheckpoint(cp); err != nil {
+			return err
 		}
 
-		if carry > 0 {
-			break // All combinations generated
+		writer, err = resumeSplitWriter(g.config.OutputFile, maxFileSize, cp.FileNumber, cp.FileOffset)
+		if err != nil {
+			return err
+		}
+		index = cp.Index
+	} else {
+		writer, err = newSplitWriter(g.config.OutputFile, maxFileSize)
+		if err != nil {
+			return err
 		}
 	}
-}
+	defer writer.Close()
 
-func (g *Generator) generateSymbolCombinations(writeFunc func(string) error) {
-	indices := make([]int, g.config.CombinationSize)
-	for i := range indices {
-		indices[i] = 0
+	it := g.iteratorAt(index)
+	it.remaining = end - index
+
+	interval := g.config.CheckpointInterval
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
 	}
+	lastCheckpoint := time.Now()
 
+	saveCheckpoint := func() error {
+		if g.config.CheckpointFile == "" {
+			return nil
+		}
+		if err := writer.Sync(); err != nil {
+			return err
+		}
+		return g.newCheckpoint(it, writer).Save(g.config.CheckpointFile)
+	}
+
+	// Progress, cancellation and checkpoints count keyspace indices, which
+	// include rule outputs dropped by the iterator, so a run dropping every
+	// output still stops when cancelled
 	for {
-		// Build base combination
-		var baseCombination strings.Builder
-		for i := 0; i < g.config.CombinationSize; i++ {
-			baseCombination.WriteString(g.passwords[indices[i]])
+		combination, kept, ok := it.step()
+		if !ok {
+			break
 		}
-		base := baseCombination.String()
-
-		// Generate combinations with symbols
-		for _, symbol := range g.config.ExtraSymbols {
-			for _, position := range g.config.SymbolPositions {
-				var combination string
-				switch position {
-				case PositionStart:
-					combination = string(symbol) + base
-				case PositionEnd:
-					combination = base + string(symbol)
-				case PositionBetween:
-					if g.config.CombinationSize > 1 {
-						parts := make([]string, g.config.CombinationSize)
-						for i := 0; i < g.config.CombinationSize; i++ {
-							parts[i] = g.passwords[indices[i]]
-						}
-						combination = strings.Join(parts[:len(parts)-1], "") + string(symbol) + parts[len(parts)-1]
-					} else {
-						combination = base + string(symbol) // For single password, treat as end
-					}
-				}
-
-				if err := writeFunc(combination); err != nil {
-					return
-				}
+
+		if kept {
+			if err := writer.WriteLine(combination); err != nil {
+				return err
+			}
+
+			progressChan <- ProgressInfo{
+				TotalCombinations: totalCombinations,
+				Generated:         it.index - start,
+				CurrentFile:       writer.Name(),
+				FileNumber:        writer.FileNumber(),
 			}
 		}
 
-		// Move to next combination
-		carry := 1
-		for i := g.config.CombinationSize - 1; i >= 0 && carry > 0; i-- {
-			indices[i] += carry
-			if indices[i] >= len(g.passwords) {
-				indices[i] = 0
-				carry = 1
-			} else {
-				carry = 0
+		if it.index%checkpointCheckEvery != 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			if cpErr := saveCheckpoint(); cpErr != nil {
+				return cpErr
+			}
+			return err
+		}
+		if time.Since(lastCheckpoint) >= interval {
+			if err := saveCheckpoint(); err != nil {
+				return err
 			}
+			lastCheckpoint = time.Now()
 		}
+	}
 
-		if carry > 0 {
-			break // All combinations generated
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	if g.config.CheckpointFile != "" {
+		if err := os.Remove(g.config.CheckpointFile); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove checkpoint: %w", err)
 		}
 	}
+	return nil
 }
 
 func (g *Generator) GetPasswordCount() int {
 	return len(g.passwords)
 }
+
+// GetRuleCount returns the number of rules loaded from the rule file.
+func (g *Generator) GetRuleCount() int {
+	return len(g.rules)
+}
+
+// GetVariantCount returns the number of input words once layout, case and
+// leet variants are added and word rules and mutators applied.
+func (g *Generator) GetVariantCount() int {
+	return g.mutated(g.inputList()).len()
+}
@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -9,6 +10,8 @@ func TestCalculateTotalCombinations(t *testing.T) {
 		name            string
 		passwords       []string
 		combinationSize int
+		minSize         int
+		maxSize         int
 		extraSymbols    []rune
 		symbolPositions []SymbolPosition
 		expected        int64
@@ -37,23 +40,59 @@ func TestCalculateTotalCombinations(t *testing.T) {
 			symbolPositions: []SymbolPosition{PositionStart, PositionEnd},
 			expected:        20, // 2^2 * (1 + 2*2) = 4 * 5 = 20
 		},
+		{
+			name:            "size range 1-3 from 3 passwords",
+			passwords:       []string{"a", "b", "c"},
+			minSize:         1,
+			maxSize:         3,
+			extraSymbols:    []rune{},
+			symbolPositions: []SymbolPosition{},
+			expected:        39, // 3 + 9 + 27
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Generator{
 				config: Config{
-					CombinationSize: tt.combinationSize,
-					ExtraSymbols:    tt.extraSymbols,
-					SymbolPositions: tt.symbolPositions,
+					CombinationSize:    tt.combinationSize,
+					MinCombinationSize: tt.minSize,
+					MaxCombinationSize: tt.maxSize,
+					ExtraSymbols:       tt.extraSymbols,
+					SymbolPositions:    tt.symbolPositions,
 				},
 				passwords: tt.passwords,
 			}
 
-			result := g.CalculateTotalCombinations()
+			result, err := g.CalculateTotalCombinations()
+			if err != nil {
+				t.Fatalf("CalculateTotalCombinations() error: %v", err)
+			}
 			if result != tt.expected {
 				t.Errorf("CalculateTotalCombinations() = %v, want %v", result, tt.expected)
 			}
 		})
 	}
 }
+
+func TestKeyspaceTooLarge(t *testing.T) {
+	passwords := make([]string, 10000)
+	for i := range passwords {
+		passwords[i] = "p"
+	}
+
+	g := &Generator{
+		config:    Config{CombinationSize: 5},
+		passwords: passwords,
+	}
+
+	if expected := "100000000000000000000"; g.Keyspace().String() != expected {
+		t.Errorf("Keyspace() = %s, want %s", g.Keyspace(), expected)
+	}
+	if _, err := g.CalculateTotalCombinations(); !errors.Is(err, ErrKeyspaceTooLarge) {
+		t.Errorf("CalculateTotalCombinations() error = %v, want ErrKeyspaceTooLarge", err)
+	}
+	if _, ok := g.Iterator().Next(); ok {
+		t.Errorf("Iterator() over an oversized keyspace yielded a candidate")
+	}
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CandidateAt computes the candidate at the given position of the keyspace
+// without enumerating the candidates before it. Outputs DropRejected would
+// skip are returned as well; a variant a candidate mutator left out is empty.
+func (g *Generator) CandidateAt(index int64) (string, error) {
+	total, err := g.KeyspaceSize()
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || index >= total {
+		return "", fmt.Errorf("index %d out of range [0, %d)", index, total)
+	}
+
+	it := g.iteratorAt(index)
+	if len(it.mutators) == 0 {
+		candidate, _ := it.Next()
+		return string(candidate), nil
+	}
+
+	// Next would skip dropped and missing variants
+	if it.variant == 0 {
+		it.expand()
+	}
+	return it.variants[it.variant], nil
+}
+
+// IndexOf reports every keyspace index that produces candidate, in ascending
+// order. A candidate can be reached from several indices when words overlap
+// (e.g. "ab"+"c" and "a"+"bc"). Rules and mutators cannot be reversed, so
+// with candidate rules or mutators IndexOf reports nothing.
+func (g *Generator) IndexOf(candidate string) []int64 {
+	if _, err := g.KeyspaceSize(); err != nil || len(g.candidateMutators()) > 0 {
+		return nil
+	}
+
+	var result []int64
+	var offset int64
+
+	for _, seg := range g.segments() {
+		seg.match(candidate, func(indices []int) {
+			if rank, ok := seg.rank(indices); ok {
+				result = append(result, offset+rank)
+			}
+		})
+		offset += seg.count().Int64()
+	}
+
+	slices.Sort(result)
+	return slices.Compact(result)
+}
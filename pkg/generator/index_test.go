@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCandidateAtMatchesIterator(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			MinCombinationSize: 1,
+			MaxCombinationSize: 3,
+			ExtraSymbols:       []rune{'!', '@'},
+			SymbolPositions:    []SymbolPosition{PositionStart, PositionEnd, PositionBetween},
+		},
+		passwords: []string{"a", "bc", "d"},
+	}
+
+	for index, expected := range collect(g) {
+		candidate, err := g.CandidateAt(int64(index))
+		if err != nil {
+			t.Fatalf("CandidateAt(%d) error: %v", index, err)
+		}
+		if candidate != expected {
+			t.Errorf("CandidateAt(%d) = %q, want %q", index, candidate, expected)
+		}
+		if indices := g.IndexOf(candidate); !slices.Contains(indices, int64(index)) {
+			t.Errorf("IndexOf(%q) = %v, missing %d", candidate, indices, index)
+		}
+	}
+
+	if _, err := g.CandidateAt(int64(len(collect(g)))); err == nil {
+		t.Errorf("CandidateAt(total) expected error")
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			CombinationSize: 2,
+			ExtraSymbols:    []rune{'!'},
+			SymbolPositions: []SymbolPosition{PositionEnd},
+		},
+		passwords: []string{"a", "ab", "b", "bb"},
+	}
+
+	tests := []struct {
+		candidate string
+		expected  []int64
+	}{
+		{"aa", []int64{0}},
+		{"abb", []int64{3, 6}}, // "a"+"bb", "ab"+"b"
+		{"ab!", []int64{18}},
+		{"zz", nil},
+	}
+
+	for _, tt := range tests {
+		if result := g.IndexOf(tt.candidate); !slices.Equal(result, tt.expected) {
+			t.Errorf("IndexOf(%q) = %v, want %v", tt.candidate, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"iter"
+	"math"
+)
+
+// Iterator yields candidates one at a time in the order GenerateCombinations
+// writes them. The slice returned by Next is reused by the following call.
+type Iterator struct {
+	segments  []*segment
+	counts    []int64
+	remaining int64
+
+	index   int64 // Keyspace index of the next candidate
+	segment int   // Segment of the next candidate, len(segments) when done
+	indices []int
+	buf     []byte
+
+	// Candidate rules and mutators, expanding every base candidate into
+	// perBase variants
+	mutators []Mutator
+	perBase  int64
+	variant  int64    // Variant returned by the next call to Next, 0 for a new base
+	variants []string // Variants of the current base
+	present  []bool   // Whether each variant was produced
+	drop     bool     // Skip rejected and repeated variants of a base
+	seen     map[string]bool
+}
+
+// Iterator returns an iterator over the configured slice of the keyspace
+// (see Config.Skip and Config.Limit).
+//
+// If the keyspace is too large to index (see KeyspaceSize) the iterator is
+// empty; check CalculateTotalCombinations for the error.
+func (g *Generator) Iterator() *Iterator {
+	start, end, err := g.Range()
+	if err != nil {
+		return &Iterator{}
+	}
+
+	it := g.iteratorAt(start)
+	it.remaining = end - start
+	return it
+}
+
+// newIterator returns an iterator over the whole keyspace.
+func (g *Generator) newIterator() *Iterator {
+	it := &Iterator{
+		segments:  g.segments(),
+		remaining: -1,
+		mutators:  g.candidateMutators(),
+		perBase:   math.MaxInt64,
+		drop:      g.config.DropRejected,
+		seen:      make(map[string]bool),
+	}
+	if n := g.candidateVariants(); n.IsInt64() {
+		it.perBase = n.Int64()
+	}
+
+	for _, seg := range it.segments {
+		it.counts = append(it.counts, seg.count().Int64())
+	}
+
+	it.enterSegment(0)
+	return it
+}
+
+// iteratorAt returns an iterator whose first candidate is the one at index.
+func (g *Generator) iteratorAt(index int64) *Iterator {
+	it := g.newIterator()
+	it.seek(index)
+	return it
+}
+
+// Seq returns the candidates as a range-over-func sequence.
+func (g *Generator) Seq() iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		it := g.Iterator()
+		for {
+			candidate, ok := it.Next()
+			if !ok || !yield(candidate) {
+				return
+			}
+		}
+	}
+}
+
+func (it *Iterator) Next() ([]byte, bool) {
+	for {
+		candidate, kept, ok := it.step()
+		if !ok || kept {
+			return candidate, ok
+		}
+	}
+}
+
+// step moves past the next keyspace index and returns its candidate, with
+// kept false for a variant that is missing or dropped. ok is false once the
+// iterator is exhausted.
+func (it *Iterator) step() (candidate []byte, kept, ok bool) {
+	if it.variant == 0 && it.segment >= len(it.segments) || it.remaining == 0 {
+		return nil, false, false
+	}
+	if it.remaining > 0 {
+		it.remaining--
+	}
+	it.index++
+
+	if len(it.mutators) == 0 {
+		it.buf = it.advance(it.buf[:0])
+		return it.buf, true, true
+	}
+
+	if it.variant == 0 {
+		it.expand()
+	}
+	i := it.variant
+	it.variant = (it.variant + 1) % it.perBase
+
+	it.buf = append(it.buf[:0], it.variants[i]...)
+	return it.buf, it.present[i] && (!it.drop || it.accept(it.buf)), true
+}
+
+// expand moves the odometer past the next base candidate and computes its
+// variants.
+func (it *Iterator) expand() {
+	it.buf = it.advance(it.buf[:0])
+	it.variants, it.present = expandCandidate(it.mutators, string(it.buf))
+	clear(it.seen)
+}
+
+// advance appends the candidate the odometer is on to dst and moves the
+// odometer to the next one.
+func (it *Iterator) advance(dst []byte) []byte {
+	seg := it.segments[it.segment]
+	dst = seg.appendTo(dst, it.indices)
+
+	if !seg.step(it.indices) {
+		// All candidates of the current segment generated
+		it.enterSegment(it.segment + 1)
+	}
+	return dst
+}
+
+// accept reports whether a variant is kept when dropping rejected and
+// repeated variants, and remembers it for the rest of the base.
+func (it *Iterator) accept(candidate []byte) bool {
+	if !acceptRuleOutput(candidate) || it.seen[string(candidate)] {
+		return false
+	}
+	it.seen[string(candidate)] = true
+	return true
+}
+
+// enterSegment moves the iterator to the first candidate of segment i,
+// skipping segments that hold no candidates.
+func (it *Iterator) enterSegment(i int) {
+	for i < len(it.counts) && it.counts[i] == 0 {
+		i++
+	}
+
+	it.segment = i
+	if i >= len(it.segments) {
+		return
+	}
+
+	seg := it.segments[i]
+	if len(it.indices) != seg.width {
+		it.indices = make([]int, seg.width)
+	}
+	seg.first(it.indices)
+}
+
+// seek positions the iterator on the candidate at index, following the same
+// ordering the odometer walks through.
+func (it *Iterator) seek(index int64) {
+	it.index = index
+	it.variant = 0
+	if index < 0 {
+		it.enterSegment(len(it.segments))
+		return
+	}
+
+	var variant int64
+	if len(it.mutators) > 0 && it.perBase > 0 {
+		index, variant = index/it.perBase, index%it.perBase
+	}
+
+	i := 0
+	for i < len(it.counts) && index >= it.counts[i] {
+		index -= it.counts[i]
+		i++
+	}
+
+	it.enterSegment(i)
+	if it.segment >= len(it.segments) {
+		return
+	}
+	it.segments[it.segment].unrank(it.indices, index)
+
+	if variant > 0 {
+		// Part way through the variants of a base, marking the ones already
+		// generated so repeats are still recognized
+		it.expand()
+		if it.drop {
+			for i, v := range it.variants[:variant] {
+				if it.present[i] {
+					it.accept([]byte(v))
+				}
+			}
+		}
+		it.variant = variant
+	}
+}
@@ -0,0 +1,196 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func collect(g *Generator) []string {
+	var out []string
+	for candidate := range g.Seq() {
+		out = append(out, string(candidate))
+	}
+	return out
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected []string
+	}{
+		{
+			name:     "base only",
+			config:   Config{CombinationSize: 2},
+			expected: []string{"aa", "ab", "ba", "bb"},
+		},
+		{
+			name: "with symbols",
+			config: Config{
+				CombinationSize: 2,
+				ExtraSymbols:    []rune{'!'},
+				SymbolPositions: []SymbolPosition{PositionStart, PositionBetween},
+			},
+			expected: []string{
+				"aa", "ab", "ba", "bb",
+				"!aa", "a!a", "!ab", "a!b", "!ba", "b!a", "!bb", "b!b",
+			},
+		},
+		{
+			name: "symbol and position vary fastest",
+			config: Config{
+				CombinationSize: 2,
+				ExtraSymbols:    []rune{'!', '@'},
+				SymbolPositions: []SymbolPosition{PositionStart, PositionEnd},
+			},
+			expected: []string{
+				"aa", "ab", "ba", "bb",
+				"!aa", "aa!", "@aa", "aa@", "!ab", "ab!", "@ab", "ab@",
+				"!ba", "ba!", "@ba", "ba@", "!bb", "bb!", "@bb", "bb@",
+			},
+		},
+		{
+			name: "up to two symbols",
+			config: Config{
+				CombinationSize: 2,
+				ExtraSymbols:    []rune{'#'},
+				SymbolPositions: []SymbolPosition{PositionStart, PositionBetween, PositionStart},
+				MaxSymbols:      2,
+			},
+			expected: []string{
+				"aa", "ab", "ba", "bb",
+				"#aa", "a#a", "#ab", "a#b", "#ba", "b#a", "#bb", "b#b",
+				"#a#a", "#a#b", "#b#a", "#b#b",
+			},
+		},
+		{
+			name: "prefixes and suffixes",
+			config: Config{
+				CombinationSize: 1,
+				ExtraSymbols:    []rune{'!'},
+				SymbolPositions: []SymbolPosition{PositionEnd},
+				Prefixes:        []string{"123"},
+				Suffixes:        []string{"@2024", "_admin"},
+			},
+			expected: []string{
+				"a", "b", "a!", "b!", "123a", "123b",
+				"a@2024", "a_admin", "b@2024", "b_admin",
+			},
+		},
+		{
+			name: "size range grouped by size",
+			config: Config{
+				MinCombinationSize: 1,
+				MaxCombinationSize: 2,
+				ExtraSymbols:       []rune{'!'},
+				SymbolPositions:    []SymbolPosition{PositionEnd},
+			},
+			expected: []string{
+				"a", "b", "a!", "b!",
+				"aa", "ab", "ba", "bb", "aa!", "ab!", "ba!", "bb!",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{config: tt.config, passwords: []string{"a", "b"}}
+
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+			if total, _ := g.CalculateTotalCombinations(); int64(len(result)) != total {
+				t.Errorf("got %d candidates, CalculateTotalCombinations() = %d", len(result), total)
+			}
+		})
+	}
+}
+
+func TestIteratorNext(t *testing.T) {
+	g := &Generator{config: Config{CombinationSize: 3}, passwords: []string{"x"}}
+
+	it := g.Iterator()
+	candidate, ok := it.Next()
+	if !ok || string(candidate) != "xxx" {
+		t.Fatalf("Next() = %q, %v, want \"xxx\", true", candidate, ok)
+	}
+	if _, ok := it.Next(); ok {
+		t.Errorf("Next() after last candidate returned true")
+	}
+}
+
+func TestSeqStopsEarly(t *testing.T) {
+	g := &Generator{config: Config{CombinationSize: 2}, passwords: []string{"a", "b", "c"}}
+
+	count := 0
+	for range g.Seq() {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+}
+
+func TestIteratorSkipLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		skip     int64
+		limit    int64
+		expected []string
+	}{
+		{"skip only", 6, 0, []string{"ca", "cb", "cc"}},
+		{"limit only", 0, 2, []string{"aa", "ab"}},
+		{"skip and limit", 2, 3, []string{"ac", "ba", "bb"}},
+		{"limit past end", 8, 5, []string{"cc"}},
+		{"skip past end", 20, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{
+				config:    Config{CombinationSize: 2, Skip: tt.skip, Limit: tt.limit},
+				passwords: []string{"a", "b", "c"},
+			}
+
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+			if total, _ := g.CalculateTotalCombinations(); total != int64(len(tt.expected)) {
+				t.Errorf("CalculateTotalCombinations() = %d, want %d", total, len(tt.expected))
+			}
+		})
+	}
+}
+
+func TestIteratorShards(t *testing.T) {
+	passwords := []string{"a", "b", "c"}
+	config := Config{
+		CombinationSize: 2,
+		ExtraSymbols:    []rune{'!'},
+		SymbolPositions: []SymbolPosition{PositionEnd},
+	}
+	all := collect(&Generator{config: config, passwords: passwords})
+
+	for _, count := range []int{1, 4, 7, 18, 25} {
+		var joined []string
+		for index := 1; index <= count; index++ {
+			config.ShardIndex, config.ShardCount = index, count
+			g := &Generator{config: config, passwords: passwords}
+
+			shard := collect(g)
+			if size := len(all) / count; len(shard) < size || len(shard) > size+1 {
+				t.Errorf("shard %d/%d has %d candidates, want %d or %d", index, count, len(shard), size, size+1)
+			}
+			joined = append(joined, shard...)
+		}
+
+		if !slices.Equal(joined, all) {
+			t.Errorf("%d shards joined = %v, want %v", count, joined, all)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package generator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// KeyboardLayout lists the characters of a layout's keys, unshifted and
+// shifted, in the order of the US QWERTY keys: the number row from ` to =,
+// then q to \, a to ' and z to /.
+type KeyboardLayout struct {
+	Keys    string
+	Shifted string
+}
+
+// KeyboardLayouts are the layouts words can be transposed between and US
+// QWERTY, by name.
+var KeyboardLayouts = map[string]KeyboardLayout{
+	"ru": { // ЙЦУКЕН
+		Keys:    "ё1234567890-=йцукенгшщзхъ\\фывапролджэячсмитьбю.",
+		Shifted: "Ё!\"№;%:?*()_+ЙЦУКЕНГШЩЗХЪ/ФЫВАПРОЛДЖЭЯЧСМИТЬБЮ,",
+	},
+	"ua": { // Ukrainian ЙЦУКЕН
+		Keys:    "'1234567890-=йцукенгшщзхїґфівапролджєячсмитьбю.",
+		Shifted: "₴!\"№;%:?*()_+ЙЦУКЕНГШЩЗХЇҐФІВАПРОЛДЖЄЯЧСМИТЬБЮ,",
+	},
+	"by": { // Belarusian ЙЦУКЕН
+		Keys:    "ё1234567890-=йцукенгшўзх'\\фывапролджэячсмітьбю.",
+		Shifted: "Ё!\"№;%:?*()_+ЙЦУКЕНГШЎЗХ'/ФЫВАПРОЛДЖЭЯЧСМІТЬБЮ,",
+	},
+	"de": { // QWERTZ
+		Keys:    "^1234567890ß´qwertzuiopü+#asdfghjklöäyxcvbnm,.-",
+		Shifted: "°!\"§$%&/()=?`QWERTZUIOPÜ*'ASDFGHJKLÖÄYXCVBNM;:_",
+	},
+	"fr": { // AZERTY
+		Keys:    "²&é\"'(-è_çà)=azertyuiop^$*qsdfghjklmùwxcvbn,;:!",
+		Shifted: "~1234567890°+AZERTYUIOP¨£µQSDFGHJKLM%WXCVBN?./§",
+	},
+}
+
+// LayoutNames returns the names of the built-in layouts, sorted.
+func LayoutNames() []string {
+	return slices.Sorted(maps.Keys(KeyboardLayouts))
+}
+
+// layoutMap translates the characters of one layout to and from QWERTY.
+type layoutMap struct {
+	toQwerty   map[rune]rune
+	fromQwerty map[rune]rune
+}
+
+// newLayoutMap pairs the keys of layout with the QWERTY keys. A character on
+// several keys (the Belarusian apostrophe) maps back to the first one.
+func newLayoutMap(layout KeyboardLayout) layoutMap {
+	var qwerty []rune
+	for _, row := range qwertyRows {
+		qwerty = append(qwerty, []rune(row.keys)...)
+	}
+	for _, row := range qwertyRows {
+		qwerty = append(qwerty, []rune(row.shifted)...)
+	}
+	keys := []rune(layout.Keys + layout.Shifted)
+
+	m := layoutMap{toQwerty: make(map[rune]rune), fromQwerty: make(map[rune]rune)}
+	for i, r := range keys[:min(len(keys), len(qwerty))] {
+		if _, ok := m.toQwerty[r]; !ok {
+			m.toQwerty[r] = qwerty[i]
+		}
+		m.fromQwerty[qwerty[i]] = r
+	}
+	return m
+}
+
+// transpose calls fn with word as typed on QWERTY keys while the layout was
+// active and the other way around. A direction applies only to words typed
+// entirely on its source layout.
+func (m layoutMap) transpose(word string, fn func(string)) {
+	for _, table := range []map[rune]rune{m.fromQwerty, m.toQwerty} {
+		if variant, ok := mapRunes(word, table); ok {
+			fn(variant)
+		}
+	}
+}
+
+// mapRunes maps every rune of word through table, failing if one is missing.
+func mapRunes(word string, table map[rune]rune) (string, bool) {
+	var b strings.Builder
+	for _, r := range word {
+		mapped, ok := table[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteRune(mapped)
+	}
+	return b.String(), true
+}
+
+// checkLayouts reports layout names that are not built in.
+func checkLayouts(names []string) error {
+	for _, name := range names {
+		if _, ok := KeyboardLayouts[name]; !ok {
+			return fmt.Errorf("unknown keyboard layout %q (valid: %s)", name, strings.Join(LayoutNames(), ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeyboardLayouts(t *testing.T) {
+	var qwerty int
+	for _, row := range qwertyRows {
+		qwerty += len(row.keys)
+	}
+
+	for name, layout := range KeyboardLayouts {
+		if n := len([]rune(layout.Keys)); n != qwerty {
+			t.Errorf("layout %s has %d keys, want %d", name, n, qwerty)
+		}
+		if n := len([]rune(layout.Shifted)); n != qwerty {
+			t.Errorf("layout %s has %d shifted keys, want %d", name, n, qwerty)
+		}
+	}
+}
+
+func TestLayoutTranspose(t *testing.T) {
+	tests := []struct {
+		layout   string
+		word     string
+		expected []string
+	}{
+		{layout: "ru", word: "пароль", expected: []string{"gfhjkm"}},
+		{layout: "ru", word: "Пароль1!", expected: []string{"Gfhjkm1!"}},
+		{layout: "ru", word: "password", expected: []string{"зфыыцщкв"}},
+		{layout: "ru", word: "p.s", expected: []string{"зюы"}},
+		{layout: "ru", word: "pароль", expected: nil},
+		{layout: "ua", word: "їжак", expected: []string{"];fr"}},
+		{layout: "de", word: "zoo", expected: []string{"yoo", "yoo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout+" "+tt.word, func(t *testing.T) {
+			var result []string
+			newLayoutMap(KeyboardLayouts[tt.layout]).transpose(tt.word, func(variant string) {
+				result = append(result, variant)
+			})
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("transpose() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLayoutVariants(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			CombinationSize: 1,
+			Layouts:         []string{"ru"},
+			CaseMutations:   []CaseMutation{CaseCapitalize},
+		},
+		passwords: []string{"пароль", "2024"},
+	}
+
+	expected := []string{"пароль", "gfhjkm", "Пароль", "Gfhjkm", "2024"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+
+	g.config.Layouts = []string{"xx"}
+	if err := g.Validate(); err == nil {
+		t.Errorf("Validate() expected error for an unknown layout")
+	}
+}
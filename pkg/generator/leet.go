@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultLeetTable is used when no leet table file is configured. Upper case
+// letters use the substitutes of their lower case form.
+var DefaultLeetTable = map[rune][]string{
+	'a': {"4", "@"},
+	'b': {"8"},
+	'e': {"3"},
+	'g': {"9"},
+	'i': {"1", "!"},
+	'l': {"1"},
+	'o': {"0"},
+	's': {"5", "$"},
+	't': {"7"},
+	'z': {"2"},
+}
+
+// LeetMode selects which leetspeak variants are added for every word.
+type LeetMode int
+
+const (
+	LeetNone    LeetMode = iota
+	LeetAll              // Every substitutable character replaced
+	LeetSubsets          // Every subset of the substitutable characters replaced
+
+	leetAtMost // First of the limited modes, see LeetAtMost
+)
+
+// LeetAtMost returns the mode replacing any combination of at most k
+// substitutable characters.
+func LeetAtMost(k int) LeetMode {
+	return leetAtMost + LeetMode(k)
+}
+
+// AtMost returns the substitution limit of a limited mode.
+func (m LeetMode) AtMost() (int, bool) {
+	if m < leetAtMost {
+		return 0, false
+	}
+	return int(m - leetAtMost), true
+}
+
+func (m LeetMode) String() string {
+	if k, ok := m.AtMost(); ok {
+		return fmt.Sprintf("max:%d", k)
+	}
+
+	switch m {
+	case LeetAll:
+		return "all"
+	case LeetSubsets:
+		return "subsets"
+	default:
+		return "none"
+	}
+}
+
+func ParseLeetMode(s string) (LeetMode, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "all":
+		return LeetAll, nil
+	case "subsets":
+		return LeetSubsets, nil
+	}
+
+	if k, ok := strings.CutPrefix(s, "max:"); ok {
+		if limit, err := strconv.Atoi(k); err == nil && limit >= 1 {
+			return LeetAtMost(limit), nil
+		}
+		return LeetNone, fmt.Errorf("invalid leet mode: %s (max takes a positive number of substitutions)", s)
+	}
+	return LeetNone, fmt.Errorf("invalid leet mode: %s (valid: all, subsets, max:K)", s)
+}
+
+// ReadLeetTable reads a substitution table with one CHAR=SUBSTITUTE pair per
+// line, e.g. "a=4". A character may be listed on several lines; empty lines
+// and lines starting with '#' (other than "#=...") are ignored.
+func ReadLeetTable(path string) (map[rune][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table := make(map[rune][]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "#") && !strings.HasPrefix(text, "#=") {
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text)
+		sub, ok := strings.CutPrefix(text[size:], "=")
+		if !ok || sub == "" {
+			return nil, fmt.Errorf("%s:%d: expected CHAR=SUBSTITUTE, got %q", path, line, text)
+		}
+		table[r] = append(table[r], sub)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("leet table %s has no substitutions", path)
+	}
+	return table, nil
+}
+
+// apply calls fn with the leet variants of word. Variants may repeat when
+// substitutes coincide; the caller deduplicates them.
+func (m LeetMode) apply(word string, table map[rune][]string, fn func(string)) {
+	if m == LeetNone {
+		return
+	}
+
+	runes := []rune(word)
+	limit, ok := m.AtMost()
+	if !ok {
+		limit = len(runes)
+	}
+
+	var buf []byte
+	var walk func(i, used int)
+	walk = func(i, used int) {
+		if i == len(runes) {
+			fn(string(buf))
+			return
+		}
+
+		mark := len(buf)
+		subs := leetSubstitutes(table, runes[i])
+		if m != LeetAll || len(subs) == 0 {
+			buf = utf8.AppendRune(buf, runes[i])
+			walk(i+1, used)
+			buf = buf[:mark]
+		}
+		if used < limit {
+			for _, sub := range subs {
+				buf = append(buf, sub...)
+				walk(i+1, used+1)
+				buf = buf[:mark]
+			}
+		}
+	}
+	walk(0, 0)
+}
+
+// leetSubstitutes returns the substitutes of r, falling back to those of its
+// lower case form.
+func leetSubstitutes(table map[rune][]string, r rune) []string {
+	if subs, ok := table[r]; ok {
+		return subs
+	}
+	return table[unicode.ToLower(r)]
+}
@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLeetMode(t *testing.T) {
+	tests := []struct {
+		input    string
+		word     string
+		expected []string
+	}{
+		{input: "all", word: "see", expected: []string{"533", "$33"}},
+		{input: "all", word: "xyz", expected: []string{"xy2"}},
+		{input: "subsets", word: "so", expected: []string{"so", "s0", "5o", "50", "$o", "$0"}},
+		{input: "max:1", word: "Tea", expected: []string{"Tea", "Te4", "Te@", "T3a", "7ea"}},
+		{input: "max:1", word: "xy", expected: []string{"xy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" "+tt.word, func(t *testing.T) {
+			m, err := ParseLeetMode(tt.input)
+			if err != nil {
+				t.Fatalf("ParseLeetMode() error: %v", err)
+			}
+			if m.String() != tt.input {
+				t.Errorf("String() = %q, want %q", m.String(), tt.input)
+			}
+
+			var result []string
+			m.apply(tt.word, DefaultLeetTable, func(variant string) { result = append(result, variant) })
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("variants = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"some", "max:0", "max:x"} {
+		if _, err := ParseLeetMode(input); err == nil {
+			t.Errorf("ParseLeetMode(%q) expected error", input)
+		}
+	}
+}
+
+func TestReadLeetTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[rune][]string
+		wantErr  bool
+	}{
+		{
+			name:     "pairs and comments",
+			content:  "# vowels\na=4\na=/-\\\n\n#=h\n",
+			expected: map[rune][]string{'a': {"4", "/-\\"}, '#': {"h"}},
+		},
+		{name: "missing substitute", content: "a=4\nb\n", wantErr: true},
+		{name: "empty", content: "# nothing\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "leet.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			table, err := ReadLeetTable(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadLeetTable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(table) != len(tt.expected) {
+				t.Errorf("table = %v, want %v", table, tt.expected)
+			}
+			for r, subs := range tt.expected {
+				if !slices.Equal(table[r], subs) {
+					t.Errorf("table[%q] = %v, want %v", r, table[r], subs)
+				}
+			}
+		})
+	}
+}
+
+func TestLeetVariantsPerWord(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "words.txt")
+	table := filepath.Join(dir, "leet.txt")
+	if err := os.WriteFile(input, []byte("oslo\n2024\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(table, []byte("o=0\ns=5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(Config{
+		InputFile:       input,
+		CombinationSize: 2,
+		CaseMutations:   []CaseMutation{CaseUpper},
+		Leet:            LeetAtMost(1),
+		LeetTable:       table,
+	})
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+
+	// oslo, 0slo, o5lo, osl0, the same for OSLO, and 2024 which has none
+	if count := g.GetVariantCount(); count != 9 {
+		t.Errorf("GetVariantCount() = %d, want 9", count)
+	}
+	if total, _ := g.CalculateTotalCombinations(); total != 9*9 {
+		t.Errorf("CalculateTotalCombinations() = %d, want %d", total, 9*9)
+	}
+
+	result := collect(g)
+	for _, candidate := range []string{"0slo2024", "OSL0o5lo"} {
+		if !slices.Contains(result, candidate) {
+			t.Errorf("candidates do not contain %q", candidate)
+		}
+	}
+
+	// Slots without an input file report their variants too
+	g = NewGenerator(Config{
+		Slots:     []SlotSpec{{File: writeWordlist(t, "oslo", "2024")}, {Range: "1-3"}},
+		Leet:      LeetAtMost(1),
+		LeetTable: table,
+	})
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+	if counts := g.SlotSourceCounts(); !slices.Equal(counts, []int{2, 3}) {
+		t.Errorf("SlotSourceCounts() = %v, want [2 3]", counts)
+	}
+	if counts := g.SlotWordCounts(); !slices.Equal(counts, []int{5, 3}) {
+		t.Errorf("SlotWordCounts() = %v, want [5 3]", counts)
+	}
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Built-in hashcat charsets, by the letter following '?' in a mask.
+var builtinCharsets = map[byte]string{
+	'l': "abcdefghijklmnopqrstuvwxyz",
+	'u': "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	'd': "0123456789",
+	'h': "0123456789abcdef",
+	'H': "0123456789ABCDEF",
+	's': " !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+	'a': "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+	'b': allBytes(),
+}
+
+// allBytes returns the bytes 0x00 to 0xff.
+func allBytes() string {
+	b := make([]byte, 256)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return string(b)
+}
+
+// maskPart is one character position of a mask: a literal or a charset.
+type maskPart struct {
+	literal string
+	charset []string // One word per character, nil for literals
+}
+
+// parseMask parses a hashcat-style mask such as "?u?l?l?d" into its
+// character positions. "??" is a literal question mark. Custom charsets ?1 to
+// ?4 are taken from custom; when custom is nil only the syntax is checked.
+func parseMask(mask string, custom *[4]string) ([]maskPart, error) {
+	if mask == "" {
+		return nil, fmt.Errorf("empty mask")
+	}
+
+	var parts []maskPart
+	for i := 0; i < len(mask); i++ {
+		if mask[i] != '?' {
+			parts = append(parts, maskPart{literal: mask[i : i+1]})
+			continue
+		}
+
+		if i+1 == len(mask) {
+			return nil, fmt.Errorf("mask %q ends with '?'", mask)
+		}
+		i++
+
+		switch c := mask[i]; {
+		case c == '?':
+			parts = append(parts, maskPart{literal: "?"})
+		case c >= '1' && c <= '4' && custom == nil:
+			parts = append(parts, maskPart{charset: []string{"?" + string(c)}})
+		default:
+			var charsets [4]string
+			if custom != nil {
+				charsets = *custom
+			}
+			charset, err := charsetRef(c, charsets)
+			if err != nil {
+				return nil, fmt.Errorf("mask %q: %w", mask, err)
+			}
+			parts = append(parts, maskPart{charset: charset})
+		}
+	}
+	return parts, nil
+}
+
+// maskSize returns the number of words a mask expands to, saturating at
+// math.MaxInt.
+func maskSize(parts []maskPart) int {
+	size := big.NewInt(1)
+	for _, p := range parts {
+		if p.charset != nil {
+			size.Mul(size, big.NewInt(int64(len(p.charset))))
+		}
+	}
+	if !size.IsInt64() || size.Int64() > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(size.Int64())
+}
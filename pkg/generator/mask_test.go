@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMask(t *testing.T) {
+	tests := []struct {
+		mask    string
+		size    int
+		wantErr bool
+	}{
+		{mask: "?d?d?d?d", size: 10000},
+		{mask: "?u?l?l", size: 26 * 26 * 26},
+		{mask: "?s", size: 33},
+		{mask: "?a", size: 95},
+		{mask: "?H?b", size: 16 * 256},
+		{mask: "x??", size: 1},
+		{mask: "", wantErr: true},
+		{mask: "?d?", wantErr: true},
+		{mask: "?x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mask, func(t *testing.T) {
+			parts, err := parseMask(tt.mask, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && maskSize(parts) != tt.size {
+				t.Errorf("maskSize() = %d, want %d", maskSize(parts), tt.size)
+			}
+		})
+	}
+}
+
+func TestMaskSlot(t *testing.T) {
+	spec, err := ParseSlotSpec("mask:?d-?d")
+	if err != nil {
+		t.Fatalf("ParseSlotSpec() error: %v", err)
+	}
+
+	g := &Generator{
+		config:    Config{CombinationSize: 2, Slots: []SlotSpec{{}, spec}},
+		passwords: []string{"a", "b"},
+	}
+
+	result := collect(g)
+	if len(result) != 200 || !slices.Equal(result[:3], []string{"a0-0", "a0-1", "a0-2"}) || result[199] != "b9-9" {
+		t.Errorf("candidates = %v ... %v, want 200 from a0-0 to b9-9", result[:min(3, len(result))], result[len(result)-1:])
+	}
+	if total, _ := g.CalculateTotalCombinations(); total != 200 {
+		t.Errorf("CalculateTotalCombinations() = %d, want 200", total)
+	}
+	if counts := g.SlotWordCounts(); !slices.Equal(counts, []int{2, 100}) {
+		t.Errorf("SlotWordCounts() = %v, want [2 100]", counts)
+	}
+	if indices := g.IndexOf("b4-2"); !slices.Equal(indices, []int64{142}) {
+		t.Errorf("IndexOf(%q) = %v, want [142]", "b4-2", indices)
+	}
+}
@@ -0,0 +1,415 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// Mode controls which tuples of words make up the combinations of one size.
+type Mode int
+
+const (
+	ModeProduct     Mode = iota // Every word in every slot (aa, ab, ba, bb)
+	ModePermutation             // No word reused within a combination (ab, ba)
+	ModeCombination             // Each unordered set of words once, in input order (ab)
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModePermutation:
+		return "permutation"
+	case ModeCombination:
+		return "combination"
+	default:
+		return "product"
+	}
+}
+
+func ParseMode(s string) (Mode, error) {
+	switch strings.TrimSpace(s) {
+	case "", "product":
+		return ModeProduct, nil
+	case "permutation":
+		return ModePermutation, nil
+	case "combination":
+		return ModeCombination, nil
+	default:
+		return ModeProduct, fmt.Errorf("invalid mode: %s (valid: product, permutation, combination)", s)
+	}
+}
+
+// countOf returns the number of k-word tuples drawn from n shared words.
+func (m Mode) countOf(n, k int) *big.Int {
+	switch {
+	case m == ModeProduct:
+		count := big.NewInt(int64(n))
+		return count.Exp(count, big.NewInt(int64(k)), nil)
+	case k > n:
+		return new(big.Int)
+	case m == ModePermutation:
+		return new(big.Int).MulRange(int64(n-k+1), int64(n))
+	default:
+		return new(big.Int).Binomial(int64(n), int64(k))
+	}
+}
+
+// first sets indices to the first tuple in the mode's order.
+func (m Mode) first(indices []int) {
+	for i := range indices {
+		if m == ModeProduct {
+			indices[i] = 0
+		} else {
+			indices[i] = i
+		}
+	}
+}
+
+// next advances indices to the following tuple in lexicographic order and
+// reports false once the last tuple has been passed.
+func (m Mode) next(indices []int, radix []int) bool {
+	k := len(indices)
+
+	switch m {
+	case ModePermutation:
+		n := radix[0]
+		for i := k - 1; i >= 0; i-- {
+			v := indices[i] + 1
+			for v < n && slices.Contains(indices[:i], v) {
+				v++
+			}
+			if v >= n {
+				continue
+			}
+
+			indices[i] = v
+			for j := i + 1; j < k; j++ {
+				indices[j] = 0
+				for slices.Contains(indices[:j], indices[j]) {
+					indices[j]++
+				}
+			}
+			return true
+		}
+		return false
+
+	case ModeCombination:
+		n := radix[0]
+		for i := k - 1; i >= 0; i-- {
+			if indices[i] < n-k+i {
+				indices[i]++
+				for j := i + 1; j < k; j++ {
+					indices[j] = indices[j-1] + 1
+				}
+				return true
+			}
+		}
+		return false
+
+	default:
+		for i := k - 1; i >= 0; i-- {
+			indices[i]++
+			if indices[i] < radix[i] {
+				return true
+			}
+			indices[i] = 0
+		}
+		return false
+	}
+}
+
+// unrank sets indices to the tuple at position r of the mode's order.
+func (m Mode) unrank(indices []int, radix []int, r int64) {
+	k := len(indices)
+
+	switch m {
+	case ModePermutation:
+		n := radix[0]
+		for i := range indices {
+			// Each choice for slot i is followed by P(n-i-1, k-i-1) tuples
+			block := ModePermutation.countOf(n-i-1, k-i-1).Int64()
+			d := int(r / block)
+			r %= block
+
+			// Pick the d-th word not used by an earlier slot
+			v := 0
+			for {
+				if !slices.Contains(indices[:i], v) {
+					if d == 0 {
+						break
+					}
+					d--
+				}
+				v++
+			}
+			indices[i] = v
+		}
+
+	case ModeCombination:
+		n := radix[0]
+		v := 0
+		for i := range indices {
+			for {
+				block := ModeCombination.countOf(n-v-1, k-i-1).Int64()
+				if r < block {
+					break
+				}
+				r -= block
+				v++
+			}
+			indices[i] = v
+			v++
+		}
+
+	default:
+		for i := k - 1; i >= 0; i-- {
+			indices[i] = int(r % int64(radix[i]))
+			r /= int64(radix[i])
+		}
+	}
+}
+
+// rank is the inverse of unrank. It returns false when indices is not a
+// tuple the mode produces.
+func (m Mode) rank(indices []int, radix []int) (int64, bool) {
+	k := len(indices)
+	var r int64
+
+	switch m {
+	case ModePermutation:
+		n := radix[0]
+		for i, index := range indices {
+			if slices.Contains(indices[:i], index) {
+				return 0, false
+			}
+			d := index
+			for _, earlier := range indices[:i] {
+				if earlier < index {
+					d--
+				}
+			}
+			r += int64(d) * ModePermutation.countOf(n-i-1, k-i-1).Int64()
+		}
+
+	case ModeCombination:
+		n := radix[0]
+		v := 0
+		for i, index := range indices {
+			if index < v {
+				return 0, false
+			}
+			for ; v < index; v++ {
+				r += ModeCombination.countOf(n-v-1, k-i-1).Int64()
+			}
+			v++
+		}
+
+	default:
+		for i, index := range indices {
+			r = r*int64(radix[i]) + int64(index)
+		}
+	}
+
+	return r, true
+}
+
+// wordGroups splits the words of a slot into runs of variants of one source
+// word. In permutation and combination mode the words of a tuple come from
+// distinct groups, so a word is not combined with its own variants.
+type wordGroups struct {
+	of    []int // Group of every word
+	start []int // First word of every group, then the number of words
+}
+
+// newWordGroups groups words by the non-decreasing source word index of each.
+func newWordGroups(sources []int) *wordGroups {
+	w := &wordGroups{of: make([]int, len(sources))}
+	for i, source := range sources {
+		if i == 0 || source != sources[i-1] {
+			w.start = append(w.start, i)
+		}
+		w.of[i] = len(w.start) - 1
+	}
+	w.start = append(w.start, len(sources))
+	return w
+}
+
+func (w *wordGroups) len() int {
+	return len(w.start) - 1
+}
+
+func (w *wordGroups) size(g int) int {
+	return w.start[g+1] - w.start[g]
+}
+
+// used reports whether a word of group g is among indices.
+func (w *wordGroups) used(indices []int, g int) bool {
+	return slices.ContainsFunc(indices, func(i int) bool { return w.of[i] == g })
+}
+
+// polynomial returns the coefficients up to x^k of the product of
+// (1 + size*x) over the groups: coefficient j is the number of sets of j
+// words from distinct groups.
+func (w *wordGroups) polynomial(k int) []*big.Int {
+	p := make([]*big.Int, k+1)
+	for j := range p {
+		p[j] = new(big.Int)
+	}
+	p[0].SetInt64(1)
+
+	var term big.Int
+	for g := range w.len() {
+		size := big.NewInt(int64(w.size(g)))
+		for j := k; j > 0; j-- {
+			p[j].Add(p[j], term.Mul(size, p[j-1]))
+		}
+	}
+	return p
+}
+
+// without divides the polynomial p by (1 + size*x), removing a group.
+func without(p []*big.Int, size int) []*big.Int {
+	q := make([]*big.Int, len(p))
+	q[0] = new(big.Int).Set(p[0])
+	for j := 1; j < len(p); j++ {
+		q[j] = new(big.Int).Mul(big.NewInt(int64(size)), q[j-1])
+		q[j].Sub(p[j], q[j])
+	}
+	return q
+}
+
+// count returns the number of k-word tuples of the mode.
+func (w *wordGroups) count(m Mode, k int) *big.Int {
+	count := w.polynomial(k)[k]
+	if m == ModePermutation {
+		count.Mul(count, new(big.Int).MulRange(1, int64(k)))
+	}
+	return count
+}
+
+// first sets indices to the first word of the first groups.
+func (w *wordGroups) first(indices []int) {
+	for i := range indices {
+		indices[i] = w.start[min(i, w.len())]
+	}
+}
+
+// next advances indices to the following tuple in lexicographic order, like
+// Mode.next, and reports false once the last tuple has been passed.
+func (w *wordGroups) next(m Mode, indices []int) bool {
+	k, n := len(indices), len(w.of)
+
+	for i := k - 1; i >= 0; i-- {
+		v := indices[i] + 1
+		if m == ModeCombination {
+			// Words of later groups must remain for the slots after i
+			if v >= n || w.of[v]+k-i > w.len() {
+				continue
+			}
+			indices[i] = v
+			for j := i + 1; j < k; j++ {
+				indices[j] = w.start[w.of[indices[j-1]]+1]
+			}
+			return true
+		}
+
+		for v < n && w.used(indices[:i], w.of[v]) {
+			v = w.start[w.of[v]+1]
+		}
+		if v >= n {
+			continue
+		}
+		indices[i] = v
+		for j := i + 1; j < k; j++ {
+			indices[j] = 0
+			for w.used(indices[:j], w.of[indices[j]]) {
+				indices[j] = w.start[w.of[indices[j]]+1]
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// unrank sets indices to the tuple at position r of the mode's order.
+func (w *wordGroups) unrank(m Mode, indices []int, r int64) {
+	k := len(indices)
+	p := w.polynomial(k)
+	g := 0
+
+	for i := range indices {
+		rest := k - i - 1
+		arrangements := new(big.Int).MulRange(1, int64(rest))
+		if m == ModeCombination {
+			arrangements.SetInt64(1)
+		} else {
+			g = 0
+		}
+
+		for ; ; g++ {
+			if m == ModePermutation && w.used(indices[:i], g) {
+				continue
+			}
+			// Each word of group g at slot i is followed by block tuples
+			q := without(p, w.size(g))
+			if m == ModeCombination {
+				p = q
+			}
+			block := new(big.Int).Mul(arrangements, q[rest]).Int64()
+			if words := block * int64(w.size(g)); r >= words {
+				r -= words
+				continue
+			}
+
+			indices[i] = w.start[g] + int(r/block)
+			r %= block
+			p = q
+			g++
+			break
+		}
+	}
+}
+
+// rank is the inverse of unrank. It returns false when indices is not a
+// tuple the mode produces.
+func (w *wordGroups) rank(m Mode, indices []int) (int64, bool) {
+	k := len(indices)
+	p := w.polynomial(k)
+	g := 0
+	var r int64
+
+	for i, index := range indices {
+		rest := k - i - 1
+		arrangements := new(big.Int).MulRange(1, int64(rest))
+		if m == ModeCombination {
+			arrangements.SetInt64(1)
+			if w.of[index] < g {
+				return 0, false
+			}
+		} else {
+			if w.used(indices[:i], w.of[index]) {
+				return 0, false
+			}
+			g = 0
+		}
+
+		for ; g <= w.of[index]; g++ {
+			if m == ModePermutation && w.used(indices[:i], g) {
+				continue
+			}
+			q := without(p, w.size(g))
+			if m == ModeCombination {
+				p = q
+			}
+			block := new(big.Int).Mul(arrangements, q[rest]).Int64()
+			if g < w.of[index] {
+				r += block * int64(w.size(g))
+				continue
+			}
+			r += block * int64(index-w.start[g])
+			p = q
+		}
+	}
+	return r, true
+}
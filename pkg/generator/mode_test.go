@@ -0,0 +1,187 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestModeOrdering(t *testing.T) {
+	for _, mode := range []Mode{ModeProduct, ModePermutation, ModeCombination} {
+		for n := 1; n <= 5; n++ {
+			for k := 1; k <= 4; k++ {
+				radix := slices.Repeat([]int{n}, k)
+				count := mode.countOf(n, k).Int64()
+
+				indices := make([]int, k)
+				mode.first(indices)
+
+				var r int64
+				for r = 0; r < count; r++ {
+					unranked := make([]int, k)
+					mode.unrank(unranked, radix, r)
+					if !slices.Equal(unranked, indices) {
+						t.Fatalf("%s n=%d k=%d: unrank(%d) = %v, want %v", mode, n, k, r, unranked, indices)
+					}
+					if rank, ok := mode.rank(indices, radix); !ok || rank != r {
+						t.Fatalf("%s n=%d k=%d: rank(%v) = %d, %v, want %d", mode, n, k, indices, rank, ok, r)
+					}
+
+					previous := slices.Clone(indices)
+					if mode.next(indices, radix) != (r < count-1) {
+						t.Fatalf("%s n=%d k=%d: next(%v) stopped at %d of %d", mode, n, k, previous, r, count)
+					}
+					if r < count-1 && slices.Compare(previous, indices) >= 0 {
+						t.Fatalf("%s n=%d k=%d: %v not after %v", mode, n, k, indices, previous)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestModeCandidates(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		expected []string
+	}{
+		{ModeProduct, []string{"aa", "ab", "ac", "ba", "bb", "bc", "ca", "cb", "cc"}},
+		{ModePermutation, []string{"ab", "ac", "ba", "bc", "ca", "cb"}},
+		{ModeCombination, []string{"ab", "ac", "bc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			g := &Generator{
+				config:    Config{CombinationSize: 2, Mode: tt.mode},
+				passwords: []string{"a", "b", "c"},
+			}
+
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+			if total, _ := g.CalculateTotalCombinations(); total != int64(len(tt.expected)) {
+				t.Errorf("CalculateTotalCombinations() = %d, want %d", total, len(tt.expected))
+			}
+			for index, candidate := range tt.expected {
+				if indices := g.IndexOf(candidate); !slices.Equal(indices, []int64{int64(index)}) {
+					t.Errorf("IndexOf(%q) = %v, want [%d]", candidate, indices, index)
+				}
+			}
+		})
+	}
+}
+
+func TestModeSizeLargerThanWordlist(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			MinCombinationSize: 1,
+			MaxCombinationSize: 3,
+			Mode:               ModePermutation,
+		},
+		passwords: []string{"a", "b"},
+	}
+
+	expected := []string{"a", "b", "ab", "ba"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+}
+
+func TestGroupedModeOrdering(t *testing.T) {
+	sources := [][]int{
+		{0, 1, 2, 3},
+		{0, 0, 1, 2, 2, 2},
+		{0, 1, 1, 1, 2, 3, 3},
+		{4, 4, 4},
+	}
+
+	for _, mode := range []Mode{ModePermutation, ModeCombination} {
+		for _, src := range sources {
+			w := newWordGroups(src)
+			for k := 1; k <= 4; k++ {
+				// Every tuple of words from distinct groups, increasing for combinations
+				var expected [][]int
+				tuple := make([]int, k)
+				for r := range ModeProduct.countOf(len(src), k).Int64() {
+					ModeProduct.unrank(tuple, slices.Repeat([]int{len(src)}, k), r)
+					ok := true
+					for i := range tuple {
+						if w.used(tuple[:i], w.of[tuple[i]]) || mode == ModeCombination && i > 0 && tuple[i] < tuple[i-1] {
+							ok = false
+						}
+					}
+					if ok {
+						expected = append(expected, slices.Clone(tuple))
+					}
+				}
+
+				if count := w.count(mode, k).Int64(); count != int64(len(expected)) {
+					t.Fatalf("%s %v k=%d: count() = %d, want %d", mode, src, k, count, len(expected))
+				}
+				if len(expected) == 0 {
+					continue
+				}
+
+				indices := make([]int, k)
+				w.first(indices)
+				for r, want := range expected {
+					if !slices.Equal(indices, want) {
+						t.Fatalf("%s %v k=%d: tuple %d = %v, want %v", mode, src, k, r, indices, want)
+					}
+					unranked := make([]int, k)
+					w.unrank(mode, unranked, int64(r))
+					if !slices.Equal(unranked, want) {
+						t.Fatalf("%s %v k=%d: unrank(%d) = %v, want %v", mode, src, k, r, unranked, want)
+					}
+					if rank, ok := w.rank(mode, want); !ok || rank != int64(r) {
+						t.Fatalf("%s %v k=%d: rank(%v) = %d, %v, want %d", mode, src, k, want, rank, ok, r)
+					}
+					if w.next(mode, indices) != (r < len(expected)-1) {
+						t.Fatalf("%s %v k=%d: next() stopped at %d of %d", mode, src, k, r, len(expected))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestModeVariantsOfOneWord(t *testing.T) {
+	tests := []struct {
+		mode     Mode
+		expected []string
+	}{
+		{ModePermutation, []string{"ab", "aB", "Ab", "AB", "ba", "bA", "Ba", "BA"}},
+		{ModeCombination, []string{"ab", "aB", "Ab", "AB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			g := &Generator{
+				config: Config{
+					CombinationSize: 2,
+					Mode:            tt.mode,
+					CaseMutations:   []CaseMutation{CasePermutations(2)},
+				},
+				passwords: []string{"a", "b"},
+			}
+
+			// aA combines a word with its own variant
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+			if total, _ := g.CalculateTotalCombinations(); total != int64(len(tt.expected)) {
+				t.Errorf("CalculateTotalCombinations() = %d, want %d", total, len(tt.expected))
+			}
+			for index, candidate := range tt.expected {
+				if indices := g.IndexOf(candidate); !slices.Equal(indices, []int64{int64(index)}) {
+					t.Errorf("IndexOf(%q) = %v, want [%d]", candidate, indices, index)
+				}
+			}
+			if indices := g.IndexOf("aA"); len(indices) != 0 {
+				t.Errorf("IndexOf(%q) = %v, want none", "aA", indices)
+			}
+		})
+	}
+}
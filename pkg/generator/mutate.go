@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CaseMutation is a case variant added for every word of a combination.
+type CaseMutation int
+
+const (
+	CaseNone             CaseMutation = iota
+	CaseLower                         // john smith
+	CaseUpper                         // JOHN SMITH
+	CaseCapitalize                    // John smith
+	CaseInvertCapitalize              // jOHN SMITH
+	CaseToggle                        // Every letter's case swapped
+
+	casePermutations // First of the permutation mutations, see CasePermutations
+)
+
+// CasePermutations returns the mutation producing every upper/lower case
+// combination of words with at most n letters.
+func CasePermutations(n int) CaseMutation {
+	return casePermutations + CaseMutation(n)
+}
+
+// Permutations returns the letter limit of a permutation mutation.
+func (m CaseMutation) Permutations() (int, bool) {
+	if m < casePermutations {
+		return 0, false
+	}
+	return int(m - casePermutations), true
+}
+
+func (m CaseMutation) String() string {
+	if n, ok := m.Permutations(); ok {
+		return fmt.Sprintf("perm:%d", n)
+	}
+
+	switch m {
+	case CaseLower:
+		return "lower"
+	case CaseUpper:
+		return "upper"
+	case CaseCapitalize:
+		return "cap"
+	case CaseInvertCapitalize:
+		return "invcap"
+	case CaseToggle:
+		return "toggle"
+	default:
+		return "none"
+	}
+}
+
+func ParseCaseMutation(s string) (CaseMutation, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "lower":
+		return CaseLower, nil
+	case "upper":
+		return CaseUpper, nil
+	case "cap":
+		return CaseCapitalize, nil
+	case "invcap":
+		return CaseInvertCapitalize, nil
+	case "toggle":
+		return CaseToggle, nil
+	}
+
+	if n, ok := strings.CutPrefix(s, "perm:"); ok {
+		if limit, err := strconv.Atoi(n); err == nil && limit >= 1 && limit <= 20 {
+			return CasePermutations(limit), nil
+		}
+		return CaseNone, fmt.Errorf("invalid case mutation: %s (perm takes a letter limit from 1 to 20)", s)
+	}
+	return CaseNone, fmt.Errorf("invalid case mutation: %s (valid: lower, upper, cap, invcap, toggle, perm:N)", s)
+}
+
+// apply calls fn with the variants of word produced by the mutation.
+func (m CaseMutation) apply(word string, fn func(string)) {
+	switch m {
+	case CaseLower:
+		fn(strings.ToLower(word))
+	case CaseUpper:
+		fn(strings.ToUpper(word))
+	case CaseCapitalize:
+		fn(capitalize(word))
+	case CaseInvertCapitalize:
+		if word == "" {
+			fn("")
+			return
+		}
+		r, size := utf8.DecodeRuneInString(word)
+		fn(string(unicode.ToLower(r)) + strings.ToUpper(word[size:]))
+	case CaseToggle:
+		fn(strings.Map(toggleCase, word))
+	default:
+		if limit, ok := m.Permutations(); ok {
+			permuteCase(word, limit, fn)
+		}
+	}
+}
+
+func toggleCase(r rune) rune {
+	if unicode.IsUpper(r) {
+		return unicode.ToLower(r)
+	}
+	return unicode.ToUpper(r)
+}
+
+// permuteCase calls fn with every case combination of word, if it has at
+// most limit letters.
+func permuteCase(word string, limit int, fn func(string)) {
+	runes := []rune(strings.ToLower(word))
+	var letters []int
+	for i, r := range runes {
+		if unicode.ToUpper(r) != r {
+			letters = append(letters, i)
+		}
+	}
+	if len(letters) > limit {
+		return
+	}
+
+	for bits := 0; bits < 1<<len(letters); bits++ {
+		variant := make([]rune, len(runes))
+		copy(variant, runes)
+		for j, i := range letters {
+			if bits&(1<<j) != 0 {
+				variant[i] = unicode.ToUpper(variant[i])
+			}
+		}
+		fn(string(variant))
+	}
+}
+
+// mutated returns the words of list followed by their layout transpositions,
+// the transliterations of those, their case variants and then their leet
+// variants, keeping each distinct string once, so variants that collapse
+// (digits, repeated words) are counted once. The word mutators then replace
+// every variant by their non-empty outputs. Every variant remembers the word
+// it comes from, for the permutation and combination modes. The result is
+// built once per list.
+func (g *Generator) mutated(list *wordList) *wordList {
+	mutators := g.wordMutators()
+	if len(g.config.Layouts) == 0 && len(g.config.Transliterations) == 0 && len(g.config.CaseMutations) == 0 && g.config.Leet == LeetNone && len(mutators) == 0 {
+		return list
+	}
+	if m, ok := g.variants[list]; ok {
+		return m
+	}
+
+	var words []string
+	var sources []int // Index in list of the word every variant comes from
+	seen := make(map[string]bool)
+	source := 0
+	add := func(word string) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+			sources = append(sources, source)
+		}
+	}
+
+	table := g.leet
+	if table == nil {
+		table = DefaultLeetTable
+	}
+
+	var layouts []layoutMap
+	for _, name := range g.config.Layouts {
+		layouts = append(layouts, newLayoutMap(KeyboardLayouts[name]))
+	}
+
+	for i, word := range list.words {
+		source = i
+		forms := []string{word}
+		for _, layout := range layouts {
+			layout.transpose(word, func(variant string) { forms = append(forms, variant) })
+		}
+		// Transliterate transpositions too, so ghbdtn reads privet with ru
+		for _, base := range forms {
+			for _, t := range g.config.Transliterations {
+				t.apply(base, func(variant string) { forms = append(forms, variant) })
+			}
+		}
+		// Case variants of the forms so far only, as the range does not see
+		// the forms appended by the loop
+		for _, base := range forms {
+			for _, m := range g.config.CaseMutations {
+				m.apply(base, func(variant string) { forms = append(forms, variant) })
+			}
+		}
+		for _, form := range forms {
+			add(form)
+		}
+		for _, form := range forms {
+			g.config.Leet.apply(form, table, add)
+		}
+	}
+	for _, m := range mutators {
+		words, sources = mutateWords(m, words, sources)
+	}
+
+	if g.variants == nil {
+		g.variants = make(map[*wordList]*wordList)
+	}
+	variants := newWordList(words)
+	variants.groups = newWordGroups(sources)
+	g.variants[list] = variants
+	return variants
+}
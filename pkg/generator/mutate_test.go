@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCaseMutation(t *testing.T) {
+	tests := []struct {
+		input    string
+		word     string
+		expected []string
+	}{
+		{input: "lower", word: "JoHn", expected: []string{"john"}},
+		{input: "upper", word: "JoHn", expected: []string{"JOHN"}},
+		{input: "cap", word: "jOHN", expected: []string{"John"}},
+		{input: "invcap", word: "john", expected: []string{"jOHN"}},
+		{input: "invcap", word: "", expected: []string{""}},
+		{input: "toggle", word: "JoHn1", expected: []string{"jOhN1"}},
+		{input: "perm:2", word: "a1b", expected: []string{"a1b", "A1b", "a1B", "A1B"}},
+		{input: "perm:2", word: "abc", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" "+tt.word, func(t *testing.T) {
+			m, err := ParseCaseMutation(tt.input)
+			if err != nil {
+				t.Fatalf("ParseCaseMutation() error: %v", err)
+			}
+			if m.String() != tt.input {
+				t.Errorf("String() = %q, want %q", m.String(), tt.input)
+			}
+
+			var result []string
+			m.apply(tt.word, func(variant string) { result = append(result, variant) })
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("variants = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"title", "perm:0", "perm:x"} {
+		if _, err := ParseCaseMutation(input); err == nil {
+			t.Errorf("ParseCaseMutation(%q) expected error", input)
+		}
+	}
+}
+
+func TestCaseMutationsPerWord(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			CombinationSize: 2,
+			CaseMutations:   []CaseMutation{CaseLower, CaseUpper, CaseCapitalize},
+		},
+		passwords: []string{"John", "smith", "1990"},
+	}
+
+	// John, john, JOHN; smith, SMITH, Smith; 1990 collapses to one variant
+	if total, _ := g.CalculateTotalCombinations(); total != 7*7 {
+		t.Errorf("CalculateTotalCombinations() = %d, want %d", total, 7*7)
+	}
+
+	result := collect(g)
+	for _, candidate := range []string{"JohnSmith", "johnSMITH", "JOHN1990"} {
+		if !slices.Contains(result, candidate) {
+			t.Errorf("candidates do not contain %q", candidate)
+		}
+	}
+	if len(result) != len(slices.Compact(slices.Sorted(slices.Values(result)))) {
+		t.Errorf("candidates contain duplicates")
+	}
+}
@@ -0,0 +1,96 @@
+package generator
+
+import "math/big"
+
+// Mutator transforms a word or a candidate into variants. Mutators are
+// chained in Config.WordMutators, applied to every word before combining, and
+// Config.CandidateMutators, applied to every generated candidate.
+type Mutator interface {
+	// Mutate calls yield with every variant of word. The word itself is only
+	// kept if it is yielded too.
+	Mutate(word string, yield func(variant string))
+
+	// Variants returns how many variants Mutate yields for any word at most.
+	// Every candidate counts this many times in the keyspace; further variants
+	// are ignored, and fewer leave gaps that are skipped without being written.
+	// Word mutators do not need an exact bound, as words are counted after
+	// mutation.
+	Variants() int
+}
+
+// mutateWords applies m to every word, keeping each distinct non-empty
+// variant once. The variants inherit the source index of their word.
+func mutateWords(m Mutator, words []string, sources []int) ([]string, []int) {
+	var result []string
+	var resultSources []int
+	seen := make(map[string]bool)
+
+	for i, word := range words {
+		m.Mutate(word, func(variant string) {
+			if variant != "" && !seen[variant] {
+				seen[variant] = true
+				result = append(result, variant)
+				resultSources = append(resultSources, sources[i])
+			}
+		})
+	}
+	return result, resultSources
+}
+
+// expandCandidate returns the variants of candidate through a chain of
+// mutators, in keyspace order: the variants of the first mutator's first
+// variant come first. ok is false at the positions of missing variants.
+func expandCandidate(mutators []Mutator, candidate string) (variants []string, ok []bool) {
+	variants, ok = []string{candidate}, []bool{true}
+
+	for _, m := range mutators {
+		n := max(m.Variants(), 0)
+		next := make([]string, len(variants)*n)
+		nextOK := make([]bool, len(variants)*n)
+
+		for i, word := range variants {
+			if !ok[i] {
+				continue
+			}
+			j := 0
+			m.Mutate(word, func(variant string) {
+				if j < n {
+					next[i*n+j], nextOK[i*n+j] = variant, true
+					j++
+				}
+			})
+		}
+		variants, ok = next, nextOK
+	}
+	return variants, ok
+}
+
+// wordMutators returns the chain applied to every word after case and leet
+// variants: word rules, then the configured word mutators.
+func (g *Generator) wordMutators() []Mutator {
+	var mutators []Mutator
+	if g.config.RulesPerWord && len(g.rules) > 0 {
+		mutators = append(mutators, ruleSet(g.rules))
+	}
+	return append(mutators, g.config.WordMutators...)
+}
+
+// candidateMutators returns the chain applied to every generated candidate:
+// candidate rules, then the configured candidate mutators.
+func (g *Generator) candidateMutators() []Mutator {
+	var mutators []Mutator
+	if !g.config.RulesPerWord && len(g.rules) > 0 {
+		mutators = append(mutators, ruleSet(g.rules))
+	}
+	return append(mutators, g.config.CandidateMutators...)
+}
+
+// candidateVariants returns how many times every base candidate counts in
+// the keyspace.
+func (g *Generator) candidateVariants() *big.Int {
+	n := big.NewInt(1)
+	for _, m := range g.candidateMutators() {
+		n.Mul(n, big.NewInt(int64(max(m.Variants(), 0))))
+	}
+	return n
+}
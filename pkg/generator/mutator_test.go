@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+// suffixMutator appends each of its suffixes.
+type suffixMutator []string
+
+func (m suffixMutator) Mutate(word string, yield func(string)) {
+	for _, s := range m {
+		yield(word + s)
+	}
+}
+
+func (m suffixMutator) Variants() int {
+	return len(m)
+}
+
+// abbrevMutator keeps a word and adds its abbreviation, if it has one.
+type abbrevMutator map[string]string
+
+func (m abbrevMutator) Mutate(word string, yield func(string)) {
+	yield(word)
+	if abbrev, ok := m[word]; ok {
+		yield(abbrev)
+	}
+}
+
+func (m abbrevMutator) Variants() int {
+	return 2
+}
+
+func TestMutators(t *testing.T) {
+	abbrev := abbrevMutator{"acme": "ac", "acmeacme": "AA"}
+
+	tests := []struct {
+		name     string
+		config   Config
+		keyspace int64
+		expected []string
+	}{
+		{
+			name:     "per word",
+			config:   Config{CombinationSize: 2, WordMutators: []Mutator{abbrev, suffixMutator{"", "!"}}},
+			keyspace: 16,
+			expected: []string{
+				"acmeacme", "acmeacme!", "acmeac", "acmeac!", "acme!acme", "acme!acme!", "acme!ac", "acme!ac!",
+				"acacme", "acacme!", "acac", "acac!", "ac!acme", "ac!acme!", "ac!ac", "ac!ac!",
+			},
+		},
+		{
+			name:     "per candidate, missing variants skipped",
+			config:   Config{CombinationSize: 2, CandidateMutators: []Mutator{abbrev, suffixMutator{"1", "2"}}},
+			keyspace: 4,
+			expected: []string{"acmeacme1", "acmeacme2", "AA1", "AA2"},
+		},
+		{
+			name:     "per candidate, single word",
+			config:   Config{CombinationSize: 1, CandidateMutators: []Mutator{suffixMutator{"1", "2"}, abbrev}},
+			keyspace: 4,
+			expected: []string{"acme1", "acme2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{config: tt.config, passwords: []string{"acme"}}
+
+			if keyspace, _ := g.KeyspaceSize(); keyspace != tt.keyspace {
+				t.Errorf("KeyspaceSize() = %d, want %d", keyspace, tt.keyspace)
+			}
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCandidateMutatorIndex(t *testing.T) {
+	g := &Generator{
+		config: Config{
+			CombinationSize:   1,
+			CandidateMutators: []Mutator{abbrevMutator{"acme": "ac"}, suffixMutator{"1", "2"}},
+		},
+		passwords: []string{"acme", "corp"},
+	}
+
+	// corp has no abbreviation, leaving a gap of two
+	expected := []string{"acme1", "acme2", "ac1", "ac2", "corp1", "corp2", "", ""}
+	for index, candidate := range expected {
+		if c, err := g.CandidateAt(int64(index)); err != nil || c != candidate {
+			t.Errorf("CandidateAt(%d) = %q, %v, want %q", index, c, err, candidate)
+		}
+	}
+	if indices := g.IndexOf("ac1"); indices != nil {
+		t.Errorf("IndexOf() = %v, want nil with candidate mutators", indices)
+	}
+
+	g.config.CandidateMutators = append(g.config.CandidateMutators, suffixMutator{})
+	if err := g.Validate(); err == nil {
+		t.Errorf("Validate() expected error for a mutator without variants")
+	}
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SymbolPosition is where a symbol is inserted into a combination.
+type SymbolPosition int
+
+const (
+	PositionNone       SymbolPosition = iota
+	PositionStart                     // Before the first word
+	PositionEnd                       // After the last word
+	PositionBetween                   // Before the last word
+	PositionBetweenAll                // Every gap between words, each on its own
+
+	positionGap // First of the gap positions, see PositionGap
+)
+
+// PositionGap returns the position between word n and word n+1.
+func PositionGap(n int) SymbolPosition {
+	return positionGap + SymbolPosition(n-1)
+}
+
+// Gap returns the gap number of a gap position.
+func (p SymbolPosition) Gap() (int, bool) {
+	if p < positionGap {
+		return 0, false
+	}
+	return int(p-positionGap) + 1, true
+}
+
+func (p SymbolPosition) String() string {
+	if gap, ok := p.Gap(); ok {
+		return fmt.Sprintf("gap%d", gap)
+	}
+
+	switch p {
+	case PositionStart:
+		return "start"
+	case PositionEnd:
+		return "end"
+	case PositionBetween:
+		return "between"
+	case PositionBetweenAll:
+		return "between-all"
+	default:
+		return "none"
+	}
+}
+
+func ParseSymbolPosition(s string) (SymbolPosition, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "start":
+		return PositionStart, nil
+	case "end":
+		return PositionEnd, nil
+	case "between":
+		return PositionBetween, nil
+	case "between-all":
+		return PositionBetweenAll, nil
+	}
+
+	if gap, ok := strings.CutPrefix(s, "gap"); ok {
+		if n, err := strconv.Atoi(gap); err == nil && n >= 1 {
+			return PositionGap(n), nil
+		}
+	}
+	return PositionNone, fmt.Errorf("invalid position: %s (valid: start, end, between, between-all, gap1..gapN)", s)
+}
+
+// symbolPositions returns the distinct positions symbols are inserted at in
+// combinations of the given size, none without symbols. Between and
+// between-all are resolved to gap positions, and gaps the size does not have
+// are dropped. A single word has no gap, so between is treated as end.
+func (g *Generator) symbolPositions(size int) []SymbolPosition {
+	if len(g.config.ExtraSymbols) == 0 {
+		return nil
+	}
+
+	var positions []SymbolPosition
+	add := func(position SymbolPosition) {
+		if !slices.Contains(positions, position) {
+			positions = append(positions, position)
+		}
+	}
+
+	for _, position := range g.config.SymbolPositions {
+		switch position {
+		case PositionNone:
+		case PositionBetween:
+			if size == 1 {
+				add(PositionEnd)
+			} else {
+				add(PositionGap(size - 1))
+			}
+		case PositionBetweenAll:
+			for gap := 1; gap < size; gap++ {
+				add(PositionGap(gap))
+			}
+		default:
+			if gap, ok := position.Gap(); !ok || gap < size {
+				add(position)
+			}
+		}
+	}
+	return positions
+}
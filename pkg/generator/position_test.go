@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSymbolPosition(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected SymbolPosition
+		wantErr  bool
+	}{
+		{input: "start", expected: PositionStart},
+		{input: " between-all ", expected: PositionBetweenAll},
+		{input: "gap1", expected: PositionGap(1)},
+		{input: "gap12", expected: PositionGap(12)},
+		{input: "gap0", wantErr: true},
+		{input: "gap", wantErr: true},
+		{input: "middle", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			position, err := ParseSymbolPosition(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSymbolPosition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && position != tt.expected {
+				t.Errorf("ParseSymbolPosition() = %v, want %v", position, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGapPositions(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []SymbolPosition
+		slots     []SlotSpec
+		expected  []string
+	}{
+		{
+			name:      "first gap",
+			positions: []SymbolPosition{PositionGap(1)},
+			expected:  []string{"abc", "a!bc"},
+		},
+		{
+			name:      "every gap",
+			positions: []SymbolPosition{PositionBetweenAll},
+			expected:  []string{"abc", "a!bc", "ab!c"},
+		},
+		{
+			name:      "between is the last gap",
+			positions: []SymbolPosition{PositionBetween, PositionGap(2), PositionGap(3)},
+			expected:  []string{"abc", "ab!c"},
+		},
+		{
+			name:      "mask and range slots",
+			positions: []SymbolPosition{PositionGap(1), PositionGap(2)},
+			slots:     []SlotSpec{{Mask: "x"}, {Range: "7-7"}, {Mask: "y"}},
+			expected:  []string{"x7y", "x!7y", "x7!y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{
+				config: Config{
+					CombinationSize: 3,
+					Mode:            ModeCombination,
+					ExtraSymbols:    []rune{'!'},
+					SymbolPositions: tt.positions,
+					Slots:           tt.slots,
+				},
+				passwords: []string{"a", "b", "c"},
+			}
+
+			if result := collect(g); !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// maxRuleOutput is the longest rule output kept when rejected outputs are
+// dropped, hashcat's limit for rule-based candidates.
+const maxRuleOutput = 256
+
+// Rule is a parsed hashcat rule: a sequence of functions applied in order.
+// Like hashcat, rules work on bytes and change the case of ASCII letters only,
+// and functions referring to a position beyond the word leave it unchanged.
+type Rule struct {
+	text string
+	ops  []ruleOp
+}
+
+// ruleOp is one rule function with its arguments.
+type ruleOp struct {
+	fn   byte
+	n, m int  // Positions and lengths
+	x, y byte // Characters
+}
+
+// ruleArgs lists the arguments each supported function takes: N and M are
+// positions (0-9, A-Z), X and Y characters.
+var ruleArgs = map[byte]string{
+	':': "", 'l': "", 'u': "", 'c': "", 'C': "", 't': "", 'T': "N",
+	'r': "", 'd': "", 'f': "", '{': "", '}': "", '$': "X", '^': "X",
+	'[': "", ']': "", 'D': "N", 'i': "NX", 'o': "NX", 's': "XY",
+	'@': "X", 'x': "NM", '\'': "N",
+}
+
+// ParseRule parses one line of a hashcat rule file. Spaces between functions
+// are ignored.
+func ParseRule(s string) (Rule, error) {
+	rule := Rule{text: s}
+
+	for i := 0; i < len(s); {
+		fn := s[i]
+		i++
+		if fn == ' ' {
+			continue
+		}
+
+		args, ok := ruleArgs[fn]
+		if !ok {
+			return Rule{}, fmt.Errorf("unknown rule function %q", fn)
+		}
+		if i+len(args) > len(s) {
+			return Rule{}, fmt.Errorf("rule function %q needs %d argument(s)", fn, len(args))
+		}
+
+		op := ruleOp{fn: fn}
+		positions := []*int{&op.n, &op.m}
+		chars := []*byte{&op.x, &op.y}
+		for _, arg := range []byte(args) {
+			c := s[i]
+			i++
+			if arg == 'X' || arg == 'Y' {
+				*chars[0] = c
+				chars = chars[1:]
+				continue
+			}
+
+			pos, ok := rulePosition(c)
+			if !ok {
+				return Rule{}, fmt.Errorf("invalid position %q for rule function %q (use 0-9 or A-Z)", c, fn)
+			}
+			*positions[0] = pos
+			positions = positions[1:]
+		}
+		rule.ops = append(rule.ops, op)
+	}
+
+	if len(rule.ops) == 0 {
+		return Rule{}, fmt.Errorf("empty rule")
+	}
+	return rule, nil
+}
+
+// rulePosition decodes a position argument: 0-9, then A-Z for 10 to 35.
+func rulePosition(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+func (r Rule) String() string {
+	return r.text
+}
+
+// ReadRuleFile reads a hashcat rule file, one rule per line. Empty lines and
+// lines starting with '#' are skipped; errors name the offending line.
+func ReadRuleFile(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []Rule
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		rule, err := ParseRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("rule file %s has no rules", path)
+	}
+	return rules, nil
+}
+
+// Apply appends the result of applying the rule to word to dst.
+func (r Rule) Apply(dst, word []byte) []byte {
+	start := len(dst)
+	dst = append(dst, word...)
+
+	for _, op := range r.ops {
+		w := dst[start:]
+		switch op.fn {
+		case 'l':
+			mapASCII(w, lowerASCII)
+		case 'u':
+			mapASCII(w, upperASCII)
+		case 'c':
+			mapASCII(w, lowerASCII)
+			mapASCII(w[:min(1, len(w))], upperASCII)
+		case 'C':
+			mapASCII(w, upperASCII)
+			mapASCII(w[:min(1, len(w))], lowerASCII)
+		case 't':
+			mapASCII(w, toggleASCII)
+		case 'T':
+			if op.n < len(w) {
+				w[op.n] = toggleASCII(w[op.n])
+			}
+		case 'r':
+			slices.Reverse(w)
+		case 'd':
+			dst = append(dst, w...)
+		case 'f':
+			for i := len(w) - 1; i >= 0; i-- {
+				dst = append(dst, w[i])
+			}
+		case '{':
+			if len(w) > 1 {
+				first := w[0]
+				copy(w, w[1:])
+				w[len(w)-1] = first
+			}
+		case '}':
+			if len(w) > 1 {
+				last := w[len(w)-1]
+				copy(w[1:], w[:len(w)-1])
+				w[0] = last
+			}
+		case '$':
+			dst = append(dst, op.x)
+		case '^':
+			dst = slices.Insert(dst, start, op.x)
+		case '[':
+			if len(w) > 0 {
+				dst = slices.Delete(dst, start, start+1)
+			}
+		case ']':
+			if len(w) > 0 {
+				dst = dst[:len(dst)-1]
+			}
+		case 'D':
+			if op.n < len(w) {
+				dst = slices.Delete(dst, start+op.n, start+op.n+1)
+			}
+		case 'i':
+			if op.n <= len(w) {
+				dst = slices.Insert(dst, start+op.n, op.x)
+			}
+		case 'o':
+			if op.n < len(w) {
+				w[op.n] = op.x
+			}
+		case 's':
+			for i := range w {
+				if w[i] == op.x {
+					w[i] = op.y
+				}
+			}
+		case '@':
+			kept := slices.DeleteFunc(w, func(c byte) bool { return c == op.x })
+			dst = dst[:start+len(kept)]
+		case 'x':
+			if op.n+op.m <= len(w) {
+				copy(w, w[op.n:op.n+op.m])
+				dst = dst[:start+op.m]
+			}
+		case '\'':
+			if op.n < len(w) {
+				dst = dst[:start+op.n]
+			}
+		}
+	}
+	return dst
+}
+
+func mapASCII(w []byte, fn func(byte) byte) {
+	for i, c := range w {
+		w[i] = fn(c)
+	}
+}
+
+func lowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func upperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func toggleASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return upperASCII(c)
+	}
+	return lowerASCII(c)
+}
+
+// ruleSet is a rule file as a Mutator: every rule yields one variant.
+type ruleSet []Rule
+
+func (rs ruleSet) Mutate(word string, yield func(string)) {
+	var buf []byte
+	for _, r := range rs {
+		buf = r.Apply(buf[:0], []byte(word))
+		yield(string(buf))
+	}
+}
+
+func (rs ruleSet) Variants() int {
+	return len(rs)
+}
+
+// acceptRuleOutput reports whether a rule output is kept when rejected
+// outputs are dropped: it must be non-empty and at most maxRuleOutput bytes.
+func acceptRuleOutput(candidate []byte) bool {
+	return len(candidate) > 0 && len(candidate) <= maxRuleOutput
+}
@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRuleApply(t *testing.T) {
+	tests := []struct {
+		rule     string
+		word     string
+		expected string
+	}{
+		{rule: ":", word: "Pass", expected: "Pass"},
+		{rule: "l", word: "PaSS", expected: "pass"},
+		{rule: "u", word: "pass", expected: "PASS"},
+		{rule: "c", word: "pASS", expected: "Pass"},
+		{rule: "C", word: "pass", expected: "pASS"},
+		{rule: "t", word: "PaSs1", expected: "pAsS1"},
+		{rule: "T1", word: "pass", expected: "pAss"},
+		{rule: "T9", word: "pass", expected: "pass"},
+		{rule: "r", word: "abc", expected: "cba"},
+		{rule: "d", word: "abc", expected: "abcabc"},
+		{rule: "f", word: "abc", expected: "abccba"},
+		{rule: "{", word: "abc", expected: "bca"},
+		{rule: "}", word: "abc", expected: "cab"},
+		{rule: "$1 $!", word: "abc", expected: "abc1!"},
+		{rule: "^1^2", word: "abc", expected: "21abc"},
+		{rule: "[", word: "abc", expected: "bc"},
+		{rule: "]", word: "abc", expected: "ab"},
+		{rule: "D1", word: "abc", expected: "ac"},
+		{rule: "i1-", word: "abc", expected: "a-bc"},
+		{rule: "i3-", word: "abc", expected: "abc-"},
+		{rule: "o0X", word: "abc", expected: "Xbc"},
+		{rule: "sa@", word: "banana", expected: "b@n@n@"},
+		{rule: "@a", word: "banana", expected: "bnn"},
+		{rule: "x13", word: "password", expected: "ass"},
+		{rule: "x19", word: "password", expected: "password"},
+		{rule: "'4", word: "password", expected: "pass"},
+		{rule: "c $2 $0 $2 $4", word: "john", expected: "John2024"},
+		{rule: "]]]]", word: "ab", expected: ""},
+		{rule: "$ ", word: "ab", expected: "ab "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.word, func(t *testing.T) {
+			rule, err := ParseRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRule() error: %v", err)
+			}
+			if result := string(rule.Apply([]byte("x"), []byte(tt.word))); result != "x"+tt.expected {
+				t.Errorf("Apply() = %q, want %q", result, "x"+tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "   ", "z", "$", "T", "Ta", "i1", "x1"} {
+		if _, err := ParseRule(input); err == nil {
+			t.Errorf("ParseRule(%q) expected error", input)
+		}
+	}
+}
+
+func TestReadRuleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.rule")
+	if err := os.WriteFile(path, []byte("# best\n:\nc $1\n\nu z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ReadRuleFile(path)
+	if err == nil || !strings.Contains(err.Error(), "test.rule:5:") {
+		t.Errorf("ReadRuleFile() error = %v, want error on line 5", err)
+	}
+
+	if err := os.WriteFile(path, []byte("# best\n:\nc $1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := ReadRuleFile(path)
+	if err != nil {
+		t.Fatalf("ReadRuleFile() error: %v", err)
+	}
+	if len(rules) != 2 || rules[1].String() != "c $1" {
+		t.Errorf("rules = %v, want [: c $1]", rules)
+	}
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		rules    string
+		keyspace int64
+		expected []string
+	}{
+		{
+			name:     "per candidate",
+			config:   Config{CombinationSize: 2},
+			rules:    ":\nc\n$1",
+			keyspace: 12,
+			expected: []string{
+				"abab", "Abab", "abab1", "abCD", "Abcd", "abCD1",
+				"CDab", "Cdab", "CDab1", "CDCD", "Cdcd", "CDCD1",
+			},
+		},
+		{
+			name:     "per candidate dropping repeats",
+			config:   Config{CombinationSize: 1, DropRejected: true},
+			rules:    ":\nl\n]]]",
+			keyspace: 6,
+			expected: []string{"ab", "CD", "cd"},
+		},
+		{
+			name:     "per word",
+			config:   Config{CombinationSize: 2, RulesPerWord: true},
+			rules:    "l\n]]]",
+			keyspace: 4,
+			expected: []string{"abab", "abcd", "cdab", "cdcd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.config.InputFile = filepath.Join(dir, "words.txt")
+			tt.config.RuleFile = filepath.Join(dir, "test.rule")
+			if err := os.WriteFile(tt.config.InputFile, []byte("ab\nCD\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(tt.config.RuleFile, []byte(tt.rules), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			g := NewGenerator(tt.config)
+			if err := g.LoadPasswords(); err != nil {
+				t.Fatalf("LoadPasswords() error: %v", err)
+			}
+
+			if keyspace, _ := g.KeyspaceSize(); keyspace != tt.keyspace {
+				t.Errorf("KeyspaceSize() = %d, want %d", keyspace, tt.keyspace)
+			}
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+
+			// Starting at any index continues the same sequence
+			for index := int64(0); index < tt.keyspace; index++ {
+				it := g.iteratorAt(index)
+				var rest []string
+				for c, ok := it.Next(); ok; c, ok = it.Next() {
+					rest = append(rest, string(c))
+				}
+				if !tt.config.DropRejected && int64(len(rest)) != tt.keyspace-index {
+					t.Errorf("%d candidates from %d, want %d", len(rest), index, tt.keyspace-index)
+				}
+				if !slices.Equal(rest, result[len(result)-len(rest):]) {
+					t.Errorf("candidates from %d = %v, want a suffix of %v", index, rest, result)
+				}
+			}
+		})
+	}
+}
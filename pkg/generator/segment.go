@@ -0,0 +1,227 @@
+package generator
+
+import (
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// segment is the part of the keyspace produced by one compiled template.
+// Its candidates are numbered by an odometer over dims, the last dim
+// turning fastest; segments are generated in the order returned by segments.
+type segment struct {
+	parts []part
+	dims  []dim
+	width int // Number of odometer indices
+
+	// Part orders chosen by the last dim, nil for parts alone. Symbol
+	// positions translated from flags share their words this way, so every
+	// position follows every base in turn.
+	alternatives [][]part
+}
+
+// part is one piece of a candidate: a literal or a word chosen by an index.
+type part struct {
+	literal string
+	view    slot // Words of the part after transforms, nil for literals
+	dim     int
+	elem    int // Position within the dim's indices
+}
+
+// dim is one digit of the odometer. A dim of the permutation or combination
+// mode spans several parts that draw distinct words from the same slot.
+type dim struct {
+	slot   slot
+	mode   Mode
+	groups *wordGroups // Source words of the slot's variants, nil if all differ
+	width  int
+	offset int // First index of the dim in the segment's indices
+	radix  []int
+}
+
+func (s *segment) layout() {
+	s.width = 0
+	for i := range s.dims {
+		d := &s.dims[i]
+		d.offset = s.width
+		d.radix = slices.Repeat([]int{d.slot.len()}, d.width)
+		s.width += d.width
+	}
+}
+
+func (d *dim) indices(indices []int) []int {
+	return indices[d.offset : d.offset+d.width]
+}
+
+func (d *dim) count() *big.Int {
+	if d.groups != nil {
+		return d.groups.count(d.mode, d.width)
+	}
+	return d.mode.countOf(d.slot.len(), d.width)
+}
+
+func (d *dim) first(indices []int) {
+	if d.groups != nil {
+		d.groups.first(d.indices(indices))
+		return
+	}
+	d.mode.first(d.indices(indices))
+}
+
+func (d *dim) next(indices []int) bool {
+	if d.groups != nil {
+		return d.groups.next(d.mode, d.indices(indices))
+	}
+	return d.mode.next(d.indices(indices), d.radix)
+}
+
+func (d *dim) unrank(indices []int, r int64) {
+	if d.groups != nil {
+		d.groups.unrank(d.mode, d.indices(indices), r)
+		return
+	}
+	d.mode.unrank(d.indices(indices), d.radix, r)
+}
+
+func (d *dim) rank(indices []int) (int64, bool) {
+	if d.groups != nil {
+		return d.groups.rank(d.mode, d.indices(indices))
+	}
+	return d.mode.rank(d.indices(indices), d.radix)
+}
+
+func (s *segment) count() *big.Int {
+	count := big.NewInt(1)
+	for i := range s.dims {
+		count.Mul(count, s.dims[i].count())
+	}
+	return count
+}
+
+func (s *segment) first(indices []int) {
+	for i := range s.dims {
+		s.dims[i].first(indices)
+	}
+}
+
+// step moves indices to the next candidate and reports false once they wrap
+// around to the first one.
+func (s *segment) step(indices []int) bool {
+	for i := len(s.dims) - 1; i >= 0; i-- {
+		d := &s.dims[i]
+		if d.next(indices) {
+			return true
+		}
+		d.first(indices)
+	}
+	return false
+}
+
+func (s *segment) unrank(indices []int, r int64) {
+	for i := len(s.dims) - 1; i >= 0; i-- {
+		d := &s.dims[i]
+		count := d.count().Int64()
+		d.unrank(indices, r%count)
+		r /= count
+	}
+}
+
+// rank is the inverse of unrank. It returns false when indices is not a
+// candidate of the segment.
+func (s *segment) rank(indices []int) (int64, bool) {
+	var r int64
+	for i := range s.dims {
+		d := &s.dims[i]
+		rank, ok := d.rank(indices)
+		if !ok {
+			return 0, false
+		}
+		r = r*d.count().Int64() + rank
+	}
+	return r, true
+}
+
+// interleave merges segments compiled from templates that differ only in
+// where their parts go, the last dim choosing between them.
+func interleave(segs []*segment) *segment {
+	if len(segs) == 1 {
+		return segs[0]
+	}
+
+	seg := &segment{dims: slices.Clone(segs[0].dims)}
+	for _, s := range segs {
+		seg.alternatives = append(seg.alternatives, s.parts)
+	}
+	choices := newWordList(make([]string, len(segs)))
+	seg.dims = append(seg.dims, dim{slot: choices, mode: ModeProduct, width: 1})
+	seg.layout()
+	return seg
+}
+
+// partsOf returns the parts of the candidate at indices.
+func (s *segment) partsOf(indices []int) []part {
+	if s.alternatives == nil {
+		return s.parts
+	}
+	return s.alternatives[indices[s.width-1]]
+}
+
+func (s *segment) appendTo(dst []byte, indices []int) []byte {
+	for _, p := range s.partsOf(indices) {
+		if p.view == nil {
+			dst = append(dst, p.literal...)
+		} else {
+			dst = append(dst, p.view.word(indices[s.dims[p.dim].offset+p.elem])...)
+		}
+	}
+	return dst
+}
+
+// match calls fn with every set of indices whose candidate equals s. The
+// slice passed to fn is only valid during the call.
+func (s *segment) match(candidate string, fn func([]int)) {
+	indices := make([]int, s.width)
+	assigned := make([]bool, s.width) // Set for indices shared by several parts
+
+	var walk func(parts []part, rest string)
+	walk = func(parts []part, rest string) {
+		if len(parts) == 0 {
+			if rest == "" {
+				fn(indices)
+			}
+			return
+		}
+
+		p := parts[0]
+		if p.view == nil {
+			if tail, ok := strings.CutPrefix(rest, p.literal); ok {
+				walk(parts[1:], tail)
+			}
+			return
+		}
+
+		at := s.dims[p.dim].offset + p.elem
+		if assigned[at] {
+			if word := p.view.word(indices[at]); strings.HasPrefix(rest, word) {
+				walk(parts[1:], rest[len(word):])
+			}
+			return
+		}
+
+		assigned[at] = true
+		p.view.matchPrefix(rest, func(index, length int) {
+			indices[at] = index
+			walk(parts[1:], rest[length:])
+		})
+		assigned[at] = false
+	}
+
+	if s.alternatives == nil {
+		walk(s.parts, candidate)
+		return
+	}
+	for i, parts := range s.alternatives {
+		indices[s.width-1] = i
+		walk(parts, candidate)
+	}
+}
@@ -0,0 +1,309 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// SlotSpec describes where the words of one combination slot come from.
+// The zero SlotSpec draws from the input wordlist.
+type SlotSpec struct {
+	File  string `json:"file,omitempty"`  // Wordlist file, one word per line
+	Mask  string `json:"mask,omitempty"`  // Hashcat-style mask, e.g. ?d?d?s
+	Range string `json:"range,omitempty"` // Number range, e.g. 1950-2030 or zero padded 0000-9999
+	Date  string `json:"date,omitempty"`  // Date range and formats, e.g. 1970..2005:DDMMYYYY,DDMM
+	Walk  string `json:"walk,omitempty"`  // Keyboard walk lengths and options, e.g. 4-8:repeat,shift=both
+}
+
+func (s SlotSpec) IsZero() bool {
+	return s == SlotSpec{}
+}
+
+func (s SlotSpec) String() string {
+	switch {
+	case s.File != "":
+		return s.File
+	case s.Mask != "":
+		return "mask:" + s.Mask
+	case s.Range != "":
+		return "range:" + s.Range
+	case s.Date != "":
+		return "date:" + s.Date
+	case s.Walk != "":
+		return "walk:" + s.Walk
+	default:
+		return "input"
+	}
+}
+
+// ParseSlotSpec parses a slot description: a wordlist file, "mask:MASK" for a
+// hashcat-style mask, "range:N-M" for a number range, "date:FROM..TO[:FORMATS]"
+// for dates, "walk:MIN-MAX[:OPTIONS]" for keyboard walks, or "input" for the
+// input wordlist.
+func ParseSlotSpec(s string) (SlotSpec, error) {
+	s = strings.TrimSpace(s)
+	if mask, ok := strings.CutPrefix(s, "mask:"); ok {
+		if _, err := parseMask(mask, nil); err != nil {
+			return SlotSpec{}, err
+		}
+		return SlotSpec{Mask: mask}, nil
+	}
+	if r, ok := strings.CutPrefix(s, "range:"); ok {
+		if _, err := parseNumberRange(r); err != nil {
+			return SlotSpec{}, err
+		}
+		return SlotSpec{Range: r}, nil
+	}
+	if date, ok := strings.CutPrefix(s, "date:"); ok {
+		if _, err := parseDateSpec(date); err != nil {
+			return SlotSpec{}, err
+		}
+		return SlotSpec{Date: date}, nil
+	}
+	if walk, ok := strings.CutPrefix(s, "walk:"); ok {
+		if _, err := parseWalkSpec(walk); err != nil {
+			return SlotSpec{}, err
+		}
+		return SlotSpec{Walk: walk}, nil
+	}
+
+	switch s {
+	case "":
+		return SlotSpec{}, fmt.Errorf("empty slot")
+	case "input":
+		return SlotSpec{}, nil
+	default:
+		return SlotSpec{File: s}, nil
+	}
+}
+
+// element returns the template placeholder equivalent to the slot.
+func (s SlotSpec) element() element {
+	switch {
+	case s.Mask != "":
+		return element{name: "mask", arg: s.Mask}
+	case s.Range != "":
+		return element{name: "range", arg: s.Range}
+	case s.Date != "":
+		return element{name: "date", arg: s.Date}
+	case s.Walk != "":
+		return element{name: "walk", arg: s.Walk}
+	default:
+		return element{name: "w", arg: s.File}
+	}
+}
+
+// slot supplies the words for one position of a combination.
+type slot interface {
+	len() int
+	word(i int) string
+	// matchPrefix calls fn for every word that is a prefix of s.
+	matchPrefix(s string, fn func(index, length int))
+}
+
+// unionSlot holds the words of several slots, one after the other.
+type unionSlot []slot
+
+func (u unionSlot) len() int {
+	n := 0
+	for _, sl := range u {
+		n += sl.len()
+	}
+	return n
+}
+
+func (u unionSlot) word(i int) string {
+	for _, sl := range u {
+		if i < sl.len() {
+			return sl.word(i)
+		}
+		i -= sl.len()
+	}
+	return ""
+}
+
+func (u unionSlot) matchPrefix(s string, fn func(index, length int)) {
+	offset := 0
+	for _, sl := range u {
+		sl.matchPrefix(s, func(index, length int) {
+			fn(offset+index, length)
+		})
+		offset += sl.len()
+	}
+}
+
+type wordList struct {
+	words   []string
+	groups  *wordGroups // Source words of variants, see mutated
+	index   map[string][]int
+	lengths []int
+}
+
+func newWordList(words []string) *wordList {
+	return &wordList{words: words}
+}
+
+func (w *wordList) len() int {
+	return len(w.words)
+}
+
+func (w *wordList) word(i int) string {
+	return w.words[i]
+}
+
+func (w *wordList) matchPrefix(s string, fn func(index, length int)) {
+	if w.index == nil {
+		w.index = make(map[string][]int, len(w.words))
+		for i, word := range w.words {
+			if _, ok := w.index[word]; !ok {
+				w.lengths = append(w.lengths, len(word))
+			}
+			w.index[word] = append(w.index[word], i)
+		}
+		slices.Sort(w.lengths)
+		w.lengths = slices.Compact(w.lengths)
+	}
+
+	for _, length := range w.lengths {
+		if length > len(s) {
+			break
+		}
+		for _, index := range w.index[s[:length]] {
+			fn(index, length)
+		}
+	}
+}
+
+// readWordlist reads non-empty, trimmed lines from path.
+func readWordlist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+// loadFiles loads the wordlists of all configured slots and of the
+// placeholders of templates.
+func (g *Generator) loadFiles(templates []*Template) error {
+	g.files = make(map[string]*wordList)
+
+	load := func(path string) error {
+		if _, ok := g.files[path]; ok {
+			return nil
+		}
+		words, err := readWordlist(path)
+		if err != nil {
+			return err
+		}
+		g.files[path] = newWordList(words)
+		return nil
+	}
+
+	for i, spec := range g.config.Slots {
+		if spec.File == "" {
+			continue
+		}
+		if err := load(spec.File); err != nil {
+			return fmt.Errorf("failed to load slot %d wordlist: %w", i+1, err)
+		}
+	}
+
+	if g.config.PrefixFile != "" {
+		if err := load(g.config.PrefixFile); err != nil {
+			return fmt.Errorf("failed to load prefix file: %w", err)
+		}
+	}
+	if g.config.SuffixFile != "" {
+		if err := load(g.config.SuffixFile); err != nil {
+			return fmt.Errorf("failed to load suffix file: %w", err)
+		}
+	}
+
+	g.leet = nil
+	if g.config.LeetTable != "" {
+		table, err := ReadLeetTable(g.config.LeetTable)
+		if err != nil {
+			return fmt.Errorf("failed to load leet table: %w", err)
+		}
+		g.leet = table
+	}
+
+	g.rules = nil
+	if g.config.RuleFile != "" {
+		rules, err := ReadRuleFile(g.config.RuleFile)
+		if err != nil {
+			return fmt.Errorf("failed to load rules: %w", err)
+		}
+		g.rules = rules
+	}
+
+	for _, t := range templates {
+		for _, path := range t.files() {
+			if err := load(path); err != nil {
+				return fmt.Errorf("failed to load template wordlist: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// affixes returns the inline affixes followed by the words of file.
+func (g *Generator) affixes(inline []string, file string) []string {
+	affixes := slices.Clone(inline)
+	if list, ok := g.files[file]; ok && file != "" {
+		affixes = append(affixes, list.words...)
+	}
+	return affixes
+}
+
+// SlotWordCounts returns the number of words available to each configured
+// slot, in slot order.
+func (g *Generator) SlotWordCounts() []int {
+	var counts []int
+	for _, spec := range g.config.Slots {
+		if spec.Mask != "" {
+			// A mask is one slot per character
+			parts, _ := parseMask(spec.Mask, &g.config.Charsets)
+			counts = append(counts, maskSize(parts))
+		} else {
+			counts = append(counts, g.resolve(spec.element()).len())
+		}
+	}
+	return counts
+}
+
+// SlotSourceCounts returns the number of words of each configured slot before
+// layout, transliteration, case and leet variants are added and word mutators
+// applied, in slot order. Slots without variants count as in SlotWordCounts.
+func (g *Generator) SlotSourceCounts() []int {
+	counts := g.SlotWordCounts()
+	for i, spec := range g.config.Slots {
+		el := spec.element()
+		switch {
+		case el.name != "w":
+		case el.arg == "":
+			counts[i] = g.inputList().len()
+		case g.files[el.arg] != nil:
+			counts[i] = g.files[el.arg].len()
+		}
+	}
+	return counts
+}
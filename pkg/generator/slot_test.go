@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeWordlist(t *testing.T, words ...string) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "words*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(strings.Join(words, "\n") + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func TestPerSlotWordlists(t *testing.T) {
+	g := NewGenerator(Config{
+		Slots: []SlotSpec{
+			{File: writeWordlist(t, "john", "anna")},
+			{File: writeWordlist(t, "1990", "2000", "2024")},
+			{File: writeWordlist(t, "!")},
+		},
+	})
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+
+	expected := []string{
+		"john1990!", "john2000!", "john2024!",
+		"anna1990!", "anna2000!", "anna2024!",
+	}
+	result := collect(g)
+	if !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+	if total, _ := g.CalculateTotalCombinations(); total != 6 {
+		t.Errorf("CalculateTotalCombinations() = %d, want 6", total)
+	}
+	if counts := g.SlotWordCounts(); !slices.Equal(counts, []int{2, 3, 1}) {
+		t.Errorf("SlotWordCounts() = %v, want [2 3 1]", counts)
+	}
+
+	for index, candidate := range expected {
+		if indices := g.IndexOf(candidate); !slices.Equal(indices, []int64{int64(index)}) {
+			t.Errorf("IndexOf(%q) = %v, want [%d]", candidate, indices, index)
+		}
+	}
+}
+
+func TestSlotsMixedWithInput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(input, []byte("a\nb\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(Config{
+		InputFile:       input,
+		CombinationSize: 3,
+		Slots:           []SlotSpec{{}, {File: writeWordlist(t, "-", "_")}},
+	})
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+
+	expected := []string{"a-a", "a-b", "a_a", "a_b", "b-a", "b-b", "b_a", "b_b"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+
+	// Input words are drawn without reuse, slot words independently
+	g.config.Mode = ModePermutation
+	expected = []string{"a-b", "a_b", "b-a", "b_a"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("permutation candidates = %v, want %v", result, expected)
+	}
+}
+
+func TestAffixFiles(t *testing.T) {
+	g := NewGenerator(Config{
+		InputFile:       writeWordlist(t, "pass"),
+		CombinationSize: 1,
+		Prefixes:        []string{"#"},
+		PrefixFile:      writeWordlist(t, "!!", "123"),
+		SuffixFile:      writeWordlist(t, "1"),
+	})
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+
+	expected := []string{"pass", "#pass", "!!pass", "123pass", "pass1"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+	if total, _ := g.CalculateTotalCombinations(); total != int64(len(expected)) {
+		t.Errorf("CalculateTotalCombinations() = %d, want %d", total, len(expected))
+	}
+}
+
+func TestRangeSlots(t *testing.T) {
+	spec, err := ParseSlotSpec("range:8-10")
+	if err != nil {
+		t.Fatalf("ParseSlotSpec() error: %v", err)
+	}
+
+	g := &Generator{
+		config: Config{
+			Slots:        []SlotSpec{{}, spec},
+			SuffixRanges: []string{"00-01"},
+			Suffixes:     []string{"!"},
+		},
+		passwords: []string{"a"},
+	}
+
+	expected := []string{
+		"a8", "a9", "a10",
+		"a8!", "a800", "a801", "a9!", "a900", "a901", "a10!", "a1000", "a1001",
+	}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+	if total, _ := g.CalculateTotalCombinations(); total != int64(len(expected)) {
+		t.Errorf("CalculateTotalCombinations() = %d, want %d", total, len(expected))
+	}
+	for index, candidate := range expected {
+		if indices := g.IndexOf(candidate); !slices.Equal(indices, []int64{int64(index)}) {
+			t.Errorf("IndexOf(%q) = %v, want [%d]", candidate, indices, index)
+		}
+	}
+
+	if _, err := ParseSlotSpec("range:10-8"); err == nil {
+		t.Errorf("expected error for range:10-8")
+	}
+}
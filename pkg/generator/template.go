@@ -0,0 +1,564 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Template describes the shape of a candidate in one string, such as
+// "{w}{sep}{w|cap}{year}{sym}". Text outside braces is copied as is ("{{"
+// and "}}" produce literal braces), and every placeholder becomes one slot
+// of the combination.
+//
+// Placeholders:
+//
+//	{w}          a word from the input wordlist
+//	{w:FILE}     a word from FILE
+//	{mask:MASK}  a hashcat-style mask such as ?u?l?l?d?d
+//	{sym}        one of the extra symbols
+//	{sym:CHARS}  one of CHARS, which may refer to charsets such as ?d or ?1
+//	{pre}        one of the prefixes
+//	{suf}        one of the suffixes
+//	{sep}        nothing or one of the separators (- _ . by default)
+//	{N-M}        a number from N to M, zero padded when N has leading zeros
+//	{year}       a number from 1950 to 2030
+//	{date:SPEC}  a date, e.g. {date:1970..2005:DDMMYYYY,DD.MM.YY} (see DefaultDateFormats)
+//	{walk:SPEC}  a keyboard walk, e.g. {walk:4-8:repeat,shift=both} (qwerty, 1qaz2wsx, !QAZ)
+//
+// A placeholder may be followed by transforms applied to its words, e.g.
+// {w|cap}: lower, upper, cap.
+type Template struct {
+	elements []element
+}
+
+type element struct {
+	literal    string // Text of a literal element
+	name       string // Source of a placeholder, empty for literals
+	arg        string
+	transforms []string
+}
+
+func (e element) isLiteral() bool {
+	return e.name == ""
+}
+
+// isWord reports whether the element stands for a word of the combination:
+// any placeholder but separators, symbols and affixes.
+func (e element) isWord() bool {
+	switch e.name {
+	case "", "sep", "sym", "pre", "suf":
+		return false
+	}
+	return true
+}
+
+func (e element) String() string {
+	if e.isLiteral() {
+		return strings.NewReplacer("{", "{{", "}", "}}").Replace(e.literal)
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	switch {
+	case e.name == "range":
+		b.WriteString(e.arg)
+	case e.arg != "":
+		b.WriteString(e.name + ":" + e.arg)
+	default:
+		b.WriteString(e.name)
+	}
+	for _, transform := range e.transforms {
+		b.WriteString("|" + transform)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+const yearRange = "1950-2030"
+
+var defaultSeparators = []string{"", "-", "_", "."}
+
+var transforms = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"cap":   capitalize,
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + strings.ToLower(s[size:])
+}
+
+func ParseTemplate(s string) (*Template, error) {
+	var t Template
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			t.elements = append(t.elements, element{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"):
+			literal.WriteByte('{')
+			i += 2
+		case strings.HasPrefix(s[i:], "}}"):
+			literal.WriteByte('}')
+			i += 2
+		case s[i] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("template: unclosed placeholder at position %d", i+1)
+			}
+
+			el, err := parsePlaceholder(s[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("template: placeholder at position %d: %w", i+1, err)
+			}
+			flush()
+			t.elements = append(t.elements, el)
+			i += end + 1
+		case s[i] == '}':
+			return nil, fmt.Errorf("template: unexpected '}' at position %d (use '}}' for a literal brace)", i+1)
+		default:
+			literal.WriteByte(s[i])
+			i++
+		}
+	}
+	flush()
+
+	if len(t.elements) == 0 {
+		return nil, fmt.Errorf("template is empty")
+	}
+	return &t, nil
+}
+
+func parsePlaceholder(body string) (element, error) {
+	fields := strings.Split(body, "|")
+	name, arg, _ := strings.Cut(fields[0], ":")
+	el := element{name: name, arg: arg, transforms: fields[1:]}
+
+	switch name {
+	case "w", "sym":
+		if name == "sym" && strings.Contains(fields[0], ":") && arg == "" {
+			return el, fmt.Errorf("empty symbol set")
+		}
+	case "mask":
+		if _, err := parseMask(arg, nil); err != nil {
+			return el, err
+		}
+	case "sep", "pre", "suf":
+		if arg != "" {
+			return el, fmt.Errorf("{%s} takes no argument", name)
+		}
+	case "year":
+		if arg != "" {
+			return el, fmt.Errorf("{year} takes no argument")
+		}
+		el.name, el.arg = "range", yearRange
+	case "range":
+		if _, err := parseNumberRange(arg); err != nil {
+			return el, err
+		}
+	case "date":
+		if _, err := parseDateSpec(arg); err != nil {
+			return el, err
+		}
+	case "walk":
+		if _, err := parseWalkSpec(arg); err != nil {
+			return el, err
+		}
+	case "":
+		return el, fmt.Errorf("empty placeholder")
+	default:
+		if _, err := parseNumberRange(name); err != nil {
+			return el, fmt.Errorf("unknown placeholder {%s}", name)
+		}
+		el.name, el.arg = "range", name
+	}
+
+	for _, transform := range el.transforms {
+		if _, ok := transforms[transform]; !ok {
+			return el, fmt.Errorf("unknown transform %q (valid: lower, upper, cap)", transform)
+		}
+	}
+
+	return el, nil
+}
+
+func (t *Template) String() string {
+	var b strings.Builder
+	for _, el := range t.elements {
+		b.WriteString(el.String())
+	}
+	return b.String()
+}
+
+// UsesInput reports whether any placeholder draws from the input wordlist.
+func (t *Template) UsesInput() bool {
+	for _, el := range t.elements {
+		if el.name == "w" && el.arg == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// files returns the wordlist files referenced by the template.
+func (t *Template) files() []string {
+	var files []string
+	for _, el := range t.elements {
+		if el.name == "w" && el.arg != "" {
+			files = append(files, el.arg)
+		}
+	}
+	return files
+}
+
+func usesInput(templates []*Template) bool {
+	return slices.ContainsFunc(templates, (*Template).UsesInput)
+}
+
+// templates returns the templates the keyspace is made of, in order.
+func (g *Generator) templates() ([]*Template, error) {
+	groups, err := g.templateGroups()
+	return slices.Concat(groups...), err
+}
+
+// templateGroups returns the templates the keyspace is made of, in generation
+// order: Config.Template, or the templates equivalent to the combination
+// sizes, slots, symbol positions and affixes. Every group becomes one
+// segment; the templates of a group place the same number of symbols at
+// different positions and are interleaved, so for every base candidate each
+// symbol is tried at each position before the next base.
+func (g *Generator) templateGroups() ([][]*Template, error) {
+	if g.config.Template != "" {
+		t, err := ParseTemplate(g.config.Template)
+		if err != nil {
+			return nil, err
+		}
+		return [][]*Template{{t}}, nil
+	}
+
+	var groups [][]*Template
+	for _, size := range g.sizes() {
+		var words []element
+		for i := range size {
+			if i > 0 && len(g.config.Separators) > 0 {
+				words = append(words, element{name: "sep"})
+			}
+			word := element{name: "w"}
+			if i < len(g.config.Slots) {
+				word = g.config.Slots[i].element()
+			}
+			words = append(words, word)
+		}
+		groups = append(groups, []*Template{{elements: words}})
+
+		// Every set of up to MaxSymbols positions gets its own template
+		positions := g.symbolPositions(size)
+		for n := 1; n <= min(g.maxSymbols(), len(positions)); n++ {
+			var group []*Template
+			subset := make([]int, n)
+			ModeCombination.first(subset)
+			for {
+				group = append(group, &Template{elements: withSymbols(words, positions, subset)})
+				if !ModeCombination.next(subset, []int{len(positions)}) {
+					break
+				}
+			}
+			groups = append(groups, group)
+		}
+
+		if g.hasPrefixes() {
+			groups = append(groups, []*Template{{elements: append([]element{{name: "pre"}}, words...)}})
+		}
+		if g.hasSuffixes() {
+			groups = append(groups, []*Template{{elements: append(slices.Clone(words), element{name: "suf"})}})
+		}
+	}
+	return groups, nil
+}
+
+// withSymbols returns words with a {sym} placeholder at each of the chosen
+// positions.
+func withSymbols(words []element, positions []SymbolPosition, chosen []int) []element {
+	symbol := element{name: "sym"}
+	elements := slices.Clone(words)
+	for _, i := range chosen {
+		switch positions[i] {
+		case PositionStart:
+			elements = slices.Insert(elements, 0, symbol)
+		case PositionEnd:
+			elements = append(elements, symbol)
+		default:
+			// Right before the word following the gap
+			gap, _ := positions[i].Gap()
+			elements = slices.Insert(elements, wordAt(elements, gap), symbol)
+		}
+	}
+	return elements
+}
+
+// wordAt returns the index in elements of word n, counting from 0.
+func wordAt(elements []element, n int) int {
+	for i, el := range elements {
+		if el.isWord() {
+			if n == 0 {
+				return i
+			}
+			n--
+		}
+	}
+	return len(elements)
+}
+
+// Templates returns the templates the keyspace is made of. Without
+// Config.Template these are translated from the combination sizes, slots and
+// symbol positions, and the templates placing the same number of symbols are
+// generated interleaved, one base candidate at a time.
+func (g *Generator) Templates() []string {
+	templates, _ := g.templates()
+
+	result := make([]string, len(templates))
+	for i, t := range templates {
+		result[i] = t.String()
+	}
+	return result
+}
+
+type numberRange struct {
+	lo, hi int64
+	width  int // Zero padded width, 0 for no padding
+}
+
+func parseNumberRange(s string) (numberRange, error) {
+	loStr, hiStr, ok := strings.Cut(s, "-")
+	if !ok || loStr == "" || hiStr == "" {
+		return numberRange{}, fmt.Errorf("invalid number range %q (expected N-M)", s)
+	}
+
+	lo, err := strconv.ParseInt(loStr, 10, 64)
+	if err != nil || lo < 0 {
+		return numberRange{}, fmt.Errorf("invalid number range %q (expected N-M)", s)
+	}
+	hi, err := strconv.ParseInt(hiStr, 10, 64)
+	if err != nil || hi < lo {
+		return numberRange{}, fmt.Errorf("invalid number range %q (expected N-M with N <= M)", s)
+	}
+	if hi-lo == math.MaxInt64 {
+		return numberRange{}, fmt.Errorf("number range %q is too large", s)
+	}
+
+	r := numberRange{lo: lo, hi: hi}
+	if len(loStr) > 1 && loStr[0] == '0' {
+		r.width = len(loStr)
+	}
+	return r, nil
+}
+
+// compile resolves the template's placeholders into slots. Input word
+// placeholders share one odometer digit in permutation and combination mode,
+// so no word, nor a variant of it, is reused between them, and with SameSeparator all {sep}
+// placeholders share one index. In templates translated from flags the
+// symbols are the last digits, turning faster than the words.
+func (g *Generator) compile(t *Template) *segment {
+	seg := &segment{}
+	group, separator := -1, -1
+	var symbols []int // Parts of the symbols placed last
+
+	for _, el := range t.elements {
+		if el.isLiteral() {
+			seg.parts = append(seg.parts, part{literal: el.literal})
+			continue
+		}
+
+		// Every character of a mask is a digit of its own
+		if el.name == "mask" {
+			parts, _ := parseMask(el.arg, &g.config.Charsets)
+			for _, p := range parts {
+				if p.charset == nil {
+					seg.parts = append(seg.parts, part{literal: p.literal})
+					continue
+				}
+				sl := newWordList(p.charset)
+				seg.parts = append(seg.parts, part{view: withTransforms(sl, el.transforms), dim: len(seg.dims)})
+				seg.dims = append(seg.dims, dim{slot: sl, mode: ModeProduct, width: 1})
+			}
+			continue
+		}
+
+		sl := g.resolve(el)
+		if el.name == "sym" && g.config.Template == "" {
+			symbols = append(symbols, len(seg.parts))
+			seg.parts = append(seg.parts, part{view: withTransforms(sl, el.transforms)})
+			continue
+		}
+
+		d, elem := len(seg.dims), 0
+		switch {
+		case el.name == "w" && el.arg == "" && g.config.Mode != ModeProduct:
+			if group < 0 {
+				group = d
+				seg.dims = append(seg.dims, dim{slot: sl, mode: g.config.Mode, groups: sl.(*wordList).groups})
+			}
+			d, elem = group, seg.dims[group].width
+			seg.dims[d].width++
+		case el.name == "sep" && g.config.SameSeparator:
+			if separator < 0 {
+				separator = d
+				seg.dims = append(seg.dims, dim{slot: sl, mode: ModeProduct, width: 1})
+			}
+			d = separator
+		default:
+			seg.dims = append(seg.dims, dim{slot: sl, mode: ModeProduct, width: 1})
+		}
+
+		seg.parts = append(seg.parts, part{view: withTransforms(sl, el.transforms), dim: d, elem: elem})
+	}
+
+	for _, i := range symbols {
+		seg.parts[i].dim = len(seg.dims)
+		seg.dims = append(seg.dims, dim{slot: seg.parts[i].view, mode: ModeProduct, width: 1})
+	}
+
+	seg.layout()
+	return seg
+}
+
+func (g *Generator) resolve(el element) slot {
+	switch el.name {
+	case "w":
+		if el.arg != "" {
+			if list, ok := g.files[el.arg]; ok {
+				return g.mutated(list)
+			}
+			return newWordList(nil)
+		}
+		return g.mutated(g.inputList())
+	case "sym":
+		symbols := el.arg
+		if symbols == "" {
+			symbols = string(g.config.ExtraSymbols)
+		}
+		words, _ := expandSymbols(symbols, g.config.Charsets)
+		return newWordList(words)
+	case "pre":
+		return newWordList(g.affixes(g.config.Prefixes, g.config.PrefixFile))
+	case "suf":
+		suffixes := unionSlot{newWordList(g.affixes(g.config.Suffixes, g.config.SuffixFile))}
+		for _, def := range g.config.SuffixRanges {
+			r, _ := parseNumberRange(def)
+			suffixes = append(suffixes, &rangeSlot{r})
+		}
+		return suffixes
+	case "sep":
+		if len(g.config.Separators) == 0 {
+			return newWordList(defaultSeparators)
+		}
+		separators := []string{""}
+		for _, separator := range g.config.Separators {
+			separators = append(separators, string(separator))
+		}
+		return newWordList(separators)
+	case "range":
+		r, _ := parseNumberRange(el.arg)
+		return &rangeSlot{r}
+	case "date":
+		return g.dateList(el.arg)
+	case "walk":
+		return g.walkList(el.arg)
+	default:
+		return newWordList(nil)
+	}
+}
+
+func (g *Generator) inputList() *wordList {
+	if g.input == nil {
+		g.input = newWordList(g.passwords)
+	}
+	return g.input
+}
+
+func withTransforms(sl slot, names []string) slot {
+	if len(names) == 0 {
+		return sl
+	}
+
+	fns := make([]func(string) string, len(names))
+	for i, name := range names {
+		fns[i] = transforms[name]
+	}
+	return &transformedSlot{slot: sl, transforms: fns}
+}
+
+// transformedSlot applies transforms to the words of another slot.
+type transformedSlot struct {
+	slot       slot
+	transforms []func(string) string
+	list       *wordList // Transformed words, built on first match
+}
+
+func (s *transformedSlot) len() int {
+	return s.slot.len()
+}
+
+func (s *transformedSlot) word(i int) string {
+	word := s.slot.word(i)
+	for _, transform := range s.transforms {
+		word = transform(word)
+	}
+	return word
+}
+
+func (s *transformedSlot) matchPrefix(prefix string, fn func(index, length int)) {
+	if s.list == nil {
+		words := make([]string, s.len())
+		for i := range words {
+			words[i] = s.word(i)
+		}
+		s.list = newWordList(words)
+	}
+	s.list.matchPrefix(prefix, fn)
+}
+
+// rangeSlot produces the numbers of a range without storing them.
+type rangeSlot struct {
+	numberRange
+}
+
+func (s *rangeSlot) len() int {
+	return int(s.hi - s.lo + 1)
+}
+
+func (s *rangeSlot) word(i int) string {
+	return fmt.Sprintf("%0*d", s.width, s.lo+int64(i))
+}
+
+func (s *rangeSlot) matchPrefix(prefix string, fn func(index, length int)) {
+	digits := 0
+	for digits < len(prefix) && prefix[digits] >= '0' && prefix[digits] <= '9' {
+		digits++
+	}
+
+	for length := 1; length <= digits && length <= 19; length++ {
+		n, err := strconv.ParseInt(prefix[:length], 10, 64)
+		if err != nil || n < s.lo || n > s.hi {
+			continue
+		}
+		if s.word(int(n-s.lo)) == prefix[:length] {
+			fn(int(n-s.lo), length)
+		}
+	}
+}
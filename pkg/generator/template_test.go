@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{input: "{w}{sep}{w|cap}{year}{sym}", expected: "{w}{sep}{w|cap}{1950-2030}{sym}"},
+		{input: "{{{w}}}-{00-99}", expected: "{{{w}}}-{00-99}"},
+		{input: "{range:1-3}{sym:!?}", expected: "{1-3}{sym:!?}"},
+		{input: "{w}{walk:4-6:down,repeat}", expected: "{w}{walk:4-6:down,repeat}"},
+		{input: "", wantErr: true},
+		{input: "{w", wantErr: true},
+		{input: "w}", wantErr: true},
+		{input: "{}", wantErr: true},
+		{input: "{word}", wantErr: true},
+		{input: "{w|title}", wantErr: true},
+		{input: "{9-1}", wantErr: true},
+		{input: "{sym:}", wantErr: true},
+		{input: "{walk:1}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tmpl.String() != tt.expected {
+				t.Errorf("String() = %q, want %q", tmpl.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestTemplateCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		mode     Mode
+		expected []string
+	}{
+		{
+			name:     "transforms and literals",
+			template: "{w|cap}-{w|upper}",
+			expected: []string{"Ab-AB", "Ab-CD", "Cd-AB", "Cd-CD"},
+		},
+		{
+			name:     "padded range and symbols",
+			template: "{w}{08-10}{sym:!}",
+			expected: []string{"ab08!", "ab09!", "ab10!", "cd08!", "cd09!", "cd10!"},
+		},
+		{
+			name:     "separators",
+			template: "{w}{sep}{w}",
+			mode:     ModePermutation,
+			expected: []string{"abcd", "ab-cd", "ab_cd", "ab.cd", "cdab", "cd-ab", "cd_ab", "cd.ab"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{
+				config:    Config{Template: tt.template, Mode: tt.mode},
+				passwords: []string{"ab", "cd"},
+			}
+
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+			for index, candidate := range tt.expected {
+				if indices := g.IndexOf(candidate); !slices.Equal(indices, []int64{int64(index)}) {
+					t.Errorf("IndexOf(%q) = %v, want [%d]", candidate, indices, index)
+				}
+				if c, err := g.CandidateAt(int64(index)); err != nil || c != candidate {
+					t.Errorf("CandidateAt(%d) = %q, %v, want %q", index, c, err, candidate)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	tests := []struct {
+		template string
+		config   Config
+		wantErr  bool
+	}{
+		{template: "{sym}{w}", wantErr: true},
+		{template: "{sym}{w}", config: Config{ExtraSymbols: []rune{'!'}}},
+		{template: "{sym:!}{w}"},
+		{template: "{pre}{w}", wantErr: true},
+		{template: "{pre}{w}", config: Config{PrefixFile: "prefixes.txt"}},
+		{template: "{w}{suf}", wantErr: true},
+		{template: "{w}{suf}", config: Config{Suffixes: []string{"1"}}},
+		{template: "{w}{suf}", config: Config{SuffixRanges: []string{"0-9"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tt.config.Template = tt.template
+			err := NewGenerator(tt.config).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplatesFromOptions(t *testing.T) {
+	g := NewGenerator(Config{
+		MinCombinationSize: 1,
+		MaxCombinationSize: 2,
+		Slots:              []SlotSpec{{}, {File: "years.txt"}},
+		ExtraSymbols:       []rune{'!'},
+		SymbolPositions:    []SymbolPosition{PositionStart, PositionBetween},
+	})
+
+	expected := []string{
+		"{w}", "{sym}{w}", "{w}{sym}",
+		"{w}{w:years.txt}", "{sym}{w}{w:years.txt}", "{w}{sym}{w:years.txt}",
+	}
+	if templates := g.Templates(); !slices.Equal(templates, expected) {
+		t.Errorf("Templates() = %v, want %v", templates, expected)
+	}
+}
+
+func TestSeparators(t *testing.T) {
+	tests := []struct {
+		name     string
+		same     bool
+		expected []string
+	}{
+		{
+			name:     "per gap",
+			expected: []string{"aaa", "aa_a", "a_aa", "a_a_a"},
+		},
+		{
+			name:     "same at every gap",
+			same:     true,
+			expected: []string{"aaa", "a_a_a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Generator{
+				config: Config{
+					CombinationSize: 3,
+					Separators:      []rune{'_'},
+					SameSeparator:   tt.same,
+				},
+				passwords: []string{"a"},
+			}
+
+			result := collect(g)
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("candidates = %v, want %v", result, tt.expected)
+			}
+			if total, _ := g.CalculateTotalCombinations(); total != int64(len(tt.expected)) {
+				t.Errorf("CalculateTotalCombinations() = %d, want %d", total, len(tt.expected))
+			}
+			for index, candidate := range tt.expected {
+				if indices := g.IndexOf(candidate); !slices.Equal(indices, []int64{int64(index)}) {
+					t.Errorf("IndexOf(%q) = %v, want [%d]", candidate, indices, index)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Transliteration is a scheme adding Latin variants of Cyrillic or Greek
+// words.
+type Transliteration int
+
+const (
+	TranslitNone     Transliteration = iota
+	TranslitGOST                     // GOST R 52535.1-2006, Russian passports: ж zh, х kh, ц tc, я ia
+	TranslitISO9                     // ISO 9:1995 with diacritics: ж ž, х h, ц c, я â
+	TranslitInformal                 // Common spellings, every alternative: ж zh/j, х kh/h/x, я ya/ja/ia
+	TranslitGreek                    // ELOT 743 for Greek words: θ th, χ ch, ου ou, αυ av/af, γγ ng
+)
+
+// transliterations holds the Latin spellings of every lower case letter, by
+// scheme. Letters with several spellings yield one variant per spelling.
+var transliterations = map[Transliteration]map[rune][]string{
+	TranslitGOST: {
+		'а': {"a"}, 'б': {"b"}, 'в': {"v"}, 'г': {"g"}, 'д': {"d"}, 'е': {"e"}, 'ё': {"e"},
+		'ж': {"zh"}, 'з': {"z"}, 'и': {"i"}, 'й': {"i"}, 'к': {"k"}, 'л': {"l"}, 'м': {"m"},
+		'н': {"n"}, 'о': {"o"}, 'п': {"p"}, 'р': {"r"}, 'с': {"s"}, 'т': {"t"}, 'у': {"u"},
+		'ф': {"f"}, 'х': {"kh"}, 'ц': {"tc"}, 'ч': {"ch"}, 'ш': {"sh"}, 'щ': {"shch"}, 'ъ': {""},
+		'ы': {"y"}, 'ь': {""}, 'э': {"e"}, 'ю': {"iu"}, 'я': {"ia"},
+		'і': {"i"}, 'ї': {"i"}, 'є': {"ie"}, 'ґ': {"g"}, 'ў': {"u"},
+	},
+	TranslitISO9: {
+		'а': {"a"}, 'б': {"b"}, 'в': {"v"}, 'г': {"g"}, 'д': {"d"}, 'е': {"e"}, 'ё': {"ë"},
+		'ж': {"ž"}, 'з': {"z"}, 'и': {"i"}, 'й': {"j"}, 'к': {"k"}, 'л': {"l"}, 'м': {"m"},
+		'н': {"n"}, 'о': {"o"}, 'п': {"p"}, 'р': {"r"}, 'с': {"s"}, 'т': {"t"}, 'у': {"u"},
+		'ф': {"f"}, 'х': {"h"}, 'ц': {"c"}, 'ч': {"č"}, 'ш': {"š"}, 'щ': {"ŝ"}, 'ъ': {"ʺ"},
+		'ы': {"y"}, 'ь': {"ʹ"}, 'э': {"è"}, 'ю': {"û"}, 'я': {"â"},
+		'і': {"ì"}, 'ї': {"ï"}, 'є': {"ê"}, 'ґ': {"g̀"}, 'ў': {"ǔ"},
+	},
+	TranslitInformal: {
+		'а': {"a"}, 'б': {"b"}, 'в': {"v", "w"}, 'г': {"g"}, 'д': {"d"}, 'е': {"e"}, 'ё': {"yo", "jo", "e"},
+		'ж': {"zh", "j"}, 'з': {"z"}, 'и': {"i", "y"}, 'й': {"y", "j", "i"}, 'к': {"k"}, 'л': {"l"}, 'м': {"m"},
+		'н': {"n"}, 'о': {"o"}, 'п': {"p"}, 'р': {"r"}, 'с': {"s"}, 'т': {"t"}, 'у': {"u"},
+		'ф': {"f"}, 'х': {"kh", "h", "x"}, 'ц': {"ts", "c", "tz"}, 'ч': {"ch"}, 'ш': {"sh"}, 'щ': {"sch", "shch"}, 'ъ': {""},
+		'ы': {"y", "i"}, 'ь': {""}, 'э': {"e"}, 'ю': {"yu", "ju", "u"}, 'я': {"ya", "ja", "ia"},
+		'і': {"i"}, 'ї': {"yi", "ji", "i"}, 'є': {"ye", "je", "e"}, 'ґ': {"g"}, 'ў': {"u", "w"},
+	},
+	TranslitGreek: {
+		'α': {"a"}, 'β': {"v"}, 'γ': {"g"}, 'δ': {"d"}, 'ε': {"e"}, 'ζ': {"z"}, 'η': {"i"},
+		'θ': {"th"}, 'ι': {"i"}, 'κ': {"k"}, 'λ': {"l"}, 'μ': {"m"}, 'ν': {"n"}, 'ξ': {"x"},
+		'ο': {"o"}, 'π': {"p"}, 'ρ': {"r"}, 'σ': {"s"}, 'ς': {"s"}, 'τ': {"t"}, 'υ': {"y"},
+		'φ': {"f"}, 'χ': {"ch"}, 'ψ': {"ps"}, 'ω': {"o"},
+		'ά': {"a"}, 'έ': {"e"}, 'ή': {"i"}, 'ί': {"i"}, 'ό': {"o"}, 'ύ': {"y"}, 'ώ': {"o"},
+		'ϊ': {"i"}, 'ϋ': {"y"}, 'ΐ': {"i"}, 'ΰ': {"y"},
+	},
+}
+
+func (t Transliteration) String() string {
+	switch t {
+	case TranslitGOST:
+		return "gost"
+	case TranslitISO9:
+		return "iso9"
+	case TranslitInformal:
+		return "informal"
+	case TranslitGreek:
+		return "greek"
+	default:
+		return "none"
+	}
+}
+
+func ParseTransliteration(s string) (Transliteration, error) {
+	switch strings.TrimSpace(s) {
+	case "gost":
+		return TranslitGOST, nil
+	case "iso9":
+		return TranslitISO9, nil
+	case "informal":
+		return TranslitInformal, nil
+	case "greek":
+		return TranslitGreek, nil
+	}
+	return TranslitNone, fmt.Errorf("invalid transliteration: %s (valid: gost, iso9, informal, greek)", s)
+}
+
+// apply calls fn with every non-empty Latin spelling of word, if it has
+// letters the scheme covers (ъ alone spells nothing in GOST). Other
+// characters are kept; upper case letters are spelled in upper case before
+// another upper case letter (ЖУК ZHUK) and capitalized otherwise (Жук Zhuk).
+func (t Transliteration) apply(word string, fn func(string)) {
+	table := transliterations[t]
+	runes := []rune(word)
+
+	var spellings [][]string
+	mapped := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		alternatives, size := table[unicode.ToLower(r)], 1
+		if t == TranslitGreek {
+			if spelling, n := greekDigraph(runes, i); n > 0 {
+				alternatives, size = []string{spelling}, n
+			}
+		}
+		if alternatives == nil {
+			spellings = append(spellings, []string{string(r)})
+			i++
+			continue
+		}
+		mapped = true
+
+		if unicode.IsUpper(r) {
+			upper := i+1 < len(runes) && unicode.IsUpper(runes[i+1])
+			recased := make([]string, len(alternatives))
+			for j, alt := range alternatives {
+				if upper {
+					recased[j] = strings.ToUpper(alt)
+				} else {
+					recased[j] = capitalize(alt)
+				}
+			}
+			alternatives = recased
+		}
+		spellings = append(spellings, alternatives)
+		i += size
+	}
+	if !mapped {
+		return
+	}
+
+	var walk func(i int, prefix string)
+	walk = func(i int, prefix string) {
+		if i == len(spellings) {
+			if prefix != "" {
+				fn(prefix)
+			}
+			return
+		}
+		for _, s := range spellings[i] {
+			walk(i+1, prefix+s)
+		}
+	}
+	walk(0, "")
+}
+
+// greekVoiced holds the vowels and voiced consonants after which αυ, ευ and ηυ
+// are spelled av, ev and iv rather than af, ef and if.
+const greekVoiced = "αάεέηήιίϊΐοόυύϋΰωώβγδζλμνρ"
+
+// greekDigraph returns the ELOT 743 spelling of the letter pair at i and its
+// length, or 0 if the pair is spelled letter by letter: ου ou, αυ ευ ηυ av
+// ev iv or af ef if, γγ ng, γκ ng (gk at the start of a word), γξ nx, γχ nch
+// and μπ b at the start of a word.
+func greekDigraph(runes []rune, i int) (string, int) {
+	if i+1 >= len(runes) {
+		return "", 0
+	}
+	a, b := unicode.ToLower(runes[i]), unicode.ToLower(runes[i+1])
+	start := i == 0 || !unicode.IsLetter(runes[i-1])
+
+	switch {
+	case a == 'ο' && (b == 'υ' || b == 'ύ'):
+		return "ou", 2
+	case (a == 'α' || a == 'ε' || a == 'η') && (b == 'υ' || b == 'ύ'):
+		if i+2 < len(runes) && strings.ContainsRune(greekVoiced, unicode.ToLower(runes[i+2])) {
+			return transliterations[TranslitGreek][a][0] + "v", 2
+		}
+		return transliterations[TranslitGreek][a][0] + "f", 2
+	case a == 'γ' && (b == 'γ' || b == 'κ' && !start):
+		return "ng", 2
+	case a == 'γ' && (b == 'ξ' || b == 'χ'):
+		return "n" + transliterations[TranslitGreek][b][0], 2
+	case a == 'μ' && b == 'π' && start:
+		return "b", 2
+	}
+	return "", 0
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTransliteration(t *testing.T) {
+	tests := []struct {
+		input    string
+		word     string
+		expected []string
+	}{
+		{input: "gost", word: "Жуковский", expected: []string{"Zhukovskii"}},
+		{input: "gost", word: "ЖУК", expected: []string{"ZHUK"}},
+		{input: "gost", word: "съезд2024", expected: []string{"sezd2024"}},
+		{input: "gost", word: "password", expected: nil},
+		{input: "gost", word: "ъь", expected: nil},
+		{input: "iso9", word: "щука", expected: []string{"ŝuka"}},
+		{input: "informal", word: "жук", expected: []string{"zhuk", "juk"}},
+		{input: "informal", word: "Юля", expected: []string{
+			"Yulya", "Yulja", "Yulia", "Julya", "Julja", "Julia", "Ulya", "Ulja", "Ulia",
+		}},
+		{input: "informal", word: "Їжак", expected: []string{
+			"Yizhak", "Yijak", "Jizhak", "Jijak", "Izhak", "Ijak",
+		}},
+		{input: "greek", word: "Αθήνα", expected: []string{"Athina"}},
+		{input: "greek", word: "Κουτσός", expected: []string{"Koutsos"}},
+		{input: "greek", word: "ΚΟΥΤΣΟΣ", expected: []string{"KOUTSOS"}},
+		{input: "greek", word: "αυτό", expected: []string{"afto"}},
+		{input: "greek", word: "Ευάγγελος", expected: []string{"Evangelos"}},
+		{input: "greek", word: "ευχαριστώ", expected: []string{"efcharisto"}},
+		{input: "greek", word: "αγκάθι", expected: []string{"angathi"}},
+		{input: "greek", word: "γκολ", expected: []string{"gkol"}},
+		{input: "greek", word: "Μπάμπης", expected: []string{"Bampis"}},
+		{input: "greek", word: "пароль", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+" "+tt.word, func(t *testing.T) {
+			m, err := ParseTransliteration(tt.input)
+			if err != nil {
+				t.Fatalf("ParseTransliteration() error: %v", err)
+			}
+			if m.String() != tt.input {
+				t.Errorf("String() = %q, want %q", m.String(), tt.input)
+			}
+
+			var result []string
+			m.apply(tt.word, func(variant string) { result = append(result, variant) })
+			if !slices.Equal(result, tt.expected) {
+				t.Errorf("variants = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "bgn", "GOST"} {
+		if _, err := ParseTransliteration(input); err == nil {
+			t.Errorf("ParseTransliteration(%q) expected error", input)
+		}
+	}
+}
+
+func TestTransliterationVariants(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(input, []byte("привет\n2024\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(Config{
+		InputFile:        input,
+		CombinationSize:  1,
+		Transliterations: []Transliteration{TranslitGOST, TranslitInformal},
+	})
+	if err := g.LoadPasswords(); err != nil {
+		t.Fatalf("LoadPasswords() error: %v", err)
+	}
+
+	// GOST privet is also the first informal spelling
+	expected := []string{"привет", "privet", "priwet", "pryvet", "prywet", "2024"}
+	if count := g.GetVariantCount(); count != len(expected) {
+		t.Errorf("GetVariantCount() = %d, want %d", count, len(expected))
+	}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+
+	// Transpositions are transliterated too
+	g = &Generator{
+		config: Config{
+			CombinationSize:  1,
+			Layouts:          []string{"ru"},
+			Transliterations: []Transliteration{TranslitGOST},
+		},
+		passwords: []string{"ghbdtn"},
+	}
+	expected = []string{"ghbdtn", "привет", "privet"}
+	if result := collect(g); !slices.Equal(result, expected) {
+		t.Errorf("candidates = %v, want %v", result, expected)
+	}
+}
@@ -0,0 +1,276 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// qwertyRows is the US QWERTY layout as rows of a grid. Rows below the first
+// start one column later, so a key's upper neighbours are the keys in its
+// column ("up") and the next column ("up-right"), e.g. a is below q and w.
+var qwertyRows = []struct {
+	column        int
+	keys, shifted string
+}{
+	{0, "`1234567890-=", "~!@#$%^&*()_+"},
+	{1, "qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{1, "asdfghjkl;'", "ASDFGHJKL:\""},
+	{1, "zxcvbnm,./", "ZXCVBNM<>?"},
+}
+
+// walkDirections are the moves to adjacent keys, as row and column offsets.
+var walkDirections = []struct {
+	name     string
+	row, col int
+}{
+	{"right", 0, 1},
+	{"left", 0, -1},
+	{"down", 1, 0},
+	{"up", -1, 0},
+	{"down-left", 1, -1},
+	{"up-right", -1, 1},
+}
+
+// maxWalkLength and maxWalkTurns bound walks, keeping their number
+// manageable.
+const (
+	maxWalkLength = 16
+	maxWalkTurns  = 3
+)
+
+// walkSpec is a parsed keyboard walk slot.
+type walkSpec struct {
+	min, max   int
+	directions []int // Indices into walkDirections
+	turns      int   // Direction changes allowed within a walk
+	repeat     bool  // Also parallel strokes such as 1qaz2wsx
+	shift      string
+}
+
+// keyPos is the grid position of a key.
+type keyPos struct {
+	row, col int
+}
+
+// parseWalkSpec parses MIN-MAX[:OPTION,...] (or N for a single length),
+// where options are directions (right, left, up, down, down-left, up-right;
+// all when none is given), turns=N, repeat, and shift=no|only|both, e.g.
+// "4-8:right,down,repeat,shift=both".
+func parseWalkSpec(s string) (walkSpec, error) {
+	lengths, options, _ := strings.Cut(s, ":")
+	spec := walkSpec{shift: "no"}
+
+	minStr, maxStr, ok := strings.Cut(lengths, "-")
+	if !ok {
+		maxStr = minStr
+	}
+	var err1, err2 error
+	spec.min, err1 = strconv.Atoi(strings.TrimSpace(minStr))
+	spec.max, err2 = strconv.Atoi(strings.TrimSpace(maxStr))
+	if err1 != nil || err2 != nil || spec.min < 2 || spec.max < spec.min || spec.max > maxWalkLength {
+		return walkSpec{}, fmt.Errorf("invalid walk lengths %q (expected MIN-MAX between 2 and %d, e.g. 4-8)", lengths, maxWalkLength)
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(option, "=")
+		switch name {
+		case "turns":
+			turns, err := strconv.Atoi(value)
+			if err != nil || turns < 0 || turns > maxWalkTurns {
+				return walkSpec{}, fmt.Errorf("invalid walk option %q (turns takes 0 to %d)", option, maxWalkTurns)
+			}
+			spec.turns = turns
+		case "repeat":
+			spec.repeat = true
+		case "shift":
+			if value != "no" && value != "only" && value != "both" {
+				return walkSpec{}, fmt.Errorf("invalid walk option %q (shift=no, only or both)", option)
+			}
+			spec.shift = value
+		default:
+			i := walkDirection(option)
+			if i < 0 {
+				return walkSpec{}, fmt.Errorf("invalid walk option %q (valid: right, left, up, down, down-left, up-right, turns=N, repeat, shift=no|only|both)", option)
+			}
+			spec.directions = append(spec.directions, i)
+		}
+	}
+
+	if spec.directions == nil {
+		for i := range walkDirections {
+			spec.directions = append(spec.directions, i)
+		}
+	}
+	return spec, nil
+}
+
+func walkDirection(name string) int {
+	for i, d := range walkDirections {
+		if d.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// walkKey returns the key at p, unshifted or shifted.
+func walkKey(p keyPos, shifted bool) (byte, bool) {
+	if p.row < 0 || p.row >= len(qwertyRows) {
+		return 0, false
+	}
+	row := qwertyRows[p.row]
+	i := p.col - row.column
+	if i < 0 || i >= len(row.keys) {
+		return 0, false
+	}
+	if shifted {
+		return row.shifted[i], true
+	}
+	return row.keys[i], true
+}
+
+// words returns the walks of the spec: continuous walks by length, then
+// parallel strokes, each from every key in layout order and keeping only the
+// first occurrence of a string.
+func (w walkSpec) words() []string {
+	var words []string
+	seen := make(map[string]bool)
+	add := func(path []keyPos) {
+		for _, shifted := range w.shiftModes() {
+			b := make([]byte, len(path))
+			for i, p := range path {
+				b[i], _ = walkKey(p, shifted)
+			}
+			if word := string(b); !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+
+	var starts []keyPos
+	for r, row := range qwertyRows {
+		for i := range row.keys {
+			starts = append(starts, keyPos{r, row.column + i})
+		}
+	}
+
+	for n := w.min; n <= w.max; n++ {
+		for _, start := range starts {
+			w.walk([]keyPos{start}, n, -1, w.turns, add)
+		}
+	}
+
+	if w.repeat {
+		for n := w.min; n <= w.max; n++ {
+			for _, start := range starts {
+				w.strokes(start, n, add)
+			}
+		}
+	}
+	return words
+}
+
+// shiftModes lists whether walks are produced unshifted, shifted or both.
+func (w walkSpec) shiftModes() []bool {
+	switch w.shift {
+	case "only":
+		return []bool{true}
+	case "both":
+		return []bool{false, true}
+	default:
+		return []bool{false}
+	}
+}
+
+// walk extends path to n keys, moving to an unvisited adjacent key in an
+// allowed direction at every step and changing direction at most turns times.
+func (w walkSpec) walk(path []keyPos, n, last, turns int, fn func([]keyPos)) {
+	if len(path) == n {
+		fn(path)
+		return
+	}
+
+	from := path[len(path)-1]
+	for _, d := range w.directions {
+		left := turns
+		if last >= 0 && d != last {
+			if turns == 0 {
+				continue
+			}
+			left--
+		}
+
+		next := keyPos{from.row + walkDirections[d].row, from.col + walkDirections[d].col}
+		if _, ok := walkKey(next, false); !ok || slices.Contains(path, next) {
+			continue
+		}
+		w.walk(append(path, next), n, d, left, fn)
+	}
+}
+
+// strokes calls fn with every walk of n keys made of two or more equal
+// straight strokes, each starting next to the start of the previous one:
+// below it for horizontal strokes (qwerasdf), to its right otherwise
+// (1qaz2wsx, 1q2w3e4r).
+func (w walkSpec) strokes(start keyPos, n int, fn func([]keyPos)) {
+	for _, d := range w.directions {
+		dir := walkDirections[d]
+		step := keyPos{0, 1}
+		if dir.row == 0 {
+			step = keyPos{1, 0}
+		}
+
+		for length := 2; length <= n/2; length++ {
+			if n%length != 0 {
+				continue
+			}
+
+			path := make([]keyPos, 0, n)
+			for s := 0; s < n/length; s++ {
+				for i := 0; i < length; i++ {
+					path = append(path, keyPos{
+						start.row + s*step.row + i*dir.row,
+						start.col + s*step.col + i*dir.col,
+					})
+				}
+			}
+			if validWalk(path) {
+				fn(path)
+			}
+		}
+	}
+}
+
+func validWalk(path []keyPos) bool {
+	for i, p := range path {
+		if _, ok := walkKey(p, false); !ok || slices.Contains(path[:i], p) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkList returns the words of a walk slot, built once per spec.
+func (g *Generator) walkList(spec string) *wordList {
+	if list, ok := g.walks[spec]; ok {
+		return list
+	}
+
+	w, err := parseWalkSpec(spec)
+	if err != nil {
+		return newWordList(nil)
+	}
+	if g.walks == nil {
+		g.walks = make(map[string]*wordList)
+	}
+	g.walks[spec] = newWordList(w.words())
+	return g.walks[spec]
+}
@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWalkSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		count    int
+		contains []string
+		excludes []string
+	}{
+		{spec: "4:right", count: 35, contains: []string{"`123", "qwer", "asdf", "zxcv"}, excludes: []string{"rewq"}},
+		{spec: "4:down", count: 10, contains: []string{"1qaz", "2wsx", "0p;/"}},
+		{spec: "4:down,shift=only", count: 10, contains: []string{"!QAZ"}, excludes: []string{"1qaz"}},
+		{spec: "4:down,shift=both", count: 20, contains: []string{"1qaz", "!QAZ"}},
+		{spec: "4:down-left", contains: []string{"0okm"}},
+		{spec: "8:down,repeat", contains: []string{"1qaz2wsx", "1q2w3e4r"}},
+		{spec: "8:right,repeat", contains: []string{"qwerasdf"}},
+		{spec: "6:right,left,down", contains: []string{"qwerty", "zxcvbn"}, excludes: []string{"qwedsa"}},
+		{spec: "6:right,left,down,turns=2", contains: []string{"qwedsa"}, excludes: []string{"qwewq1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := parseWalkSpec(tt.spec)
+			if err != nil {
+				t.Fatalf("parseWalkSpec() error: %v", err)
+			}
+
+			words := spec.words()
+			if tt.count > 0 && len(words) != tt.count {
+				t.Errorf("got %d walks, want %d", len(words), tt.count)
+			}
+			for _, word := range tt.contains {
+				if !slices.Contains(words, word) {
+					t.Errorf("walks do not contain %q", word)
+				}
+			}
+			for _, word := range tt.excludes {
+				if slices.Contains(words, word) {
+					t.Errorf("walks contain %q", word)
+				}
+			}
+			if len(words) != len(slices.Compact(slices.Sorted(slices.Values(words)))) {
+				t.Errorf("walks contain duplicates")
+			}
+		})
+	}
+
+	for _, spec := range []string{"1", "4-2", "20", "x-4", "4:sideways", "4:shift=maybe", "4:turns=9"} {
+		if _, err := parseWalkSpec(spec); err == nil {
+			t.Errorf("parseWalkSpec(%q) expected error", spec)
+		}
+	}
+}
+
+func TestWalkSlot(t *testing.T) {
+	g := &Generator{
+		config:    Config{Slots: []SlotSpec{{}, {Walk: "4:down"}}},
+		passwords: []string{"a", "b"},
+	}
+
+	if total, _ := g.CalculateTotalCombinations(); total != 2*10 {
+		t.Errorf("CalculateTotalCombinations() = %d, want %d", total, 2*10)
+	}
+	result := collect(g)
+	if len(result) != 20 || result[0] != "a1qaz" || result[19] != "b0p;/" {
+		t.Errorf("candidates = %v, want a1qaz ... b0p;/", result)
+	}
+	if indices := g.IndexOf("b2wsx"); !slices.Equal(indices, []int64{11}) {
+		t.Errorf("IndexOf(%q) = %v, want [11]", "b2wsx", indices)
+	}
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// splitWriter writes candidates line by line and starts a new numbered file
+// whenever the next line would exceed maxSize.
+type splitWriter struct {
+	outputFile     string
+	baseDir        string
+	nameWithoutExt string
+	ext            string
+	maxSize        int64
+
+	file   *os.File
+	buf    *bufio.Writer
+	name   string
+	size   int64
+	number int
+}
+
+func newSplitWriter(outputFile string, maxSize int64) (*splitWriter, error) {
+	w := makeSplitWriter(outputFile, maxSize)
+	if err := w.open(1); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// resumeSplitWriter reopens file number num and discards everything after
+// offset, including any partial trailing line left by an interrupted run.
+func resumeSplitWriter(outputFile string, maxSize int64, num int, offset int64) (*splitWriter, error) {
+	w := makeSplitWriter(outputFile, maxSize)
+
+	file, err := os.OpenFile(w.fileName(num), os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	if info.Size() < offset {
+		file.Close()
+		return nil, fmt.Errorf("output file %s is shorter than the checkpoint offset", file.Name())
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate output file: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek output file: %w", err)
+	}
+
+	w.setFile(file, num)
+	w.size = offset
+	return w, nil
+}
+
+func makeSplitWriter(outputFile string, maxSize int64) *splitWriter {
+	baseName := filepath.Base(outputFile)
+	ext := filepath.Ext(baseName)
+
+	return &splitWriter{
+		outputFile:     outputFile,
+		baseDir:        filepath.Dir(outputFile),
+		nameWithoutExt: strings.TrimSuffix(baseName, ext),
+		ext:            ext,
+		maxSize:        maxSize,
+	}
+}
+
+func (w *splitWriter) fileName(num int) string {
+	if num == 1 {
+		return w.outputFile
+	}
+	return filepath.Join(w.baseDir, fmt.Sprintf("%s_%d%s", w.nameWithoutExt, num, w.ext))
+}
+
+func (w *splitWriter) open(num int) error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	file, err := os.Create(w.fileName(num))
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	w.setFile(file, num)
+	return nil
+}
+
+func (w *splitWriter) setFile(file *os.File, num int) {
+	w.file = file
+	w.buf = bufio.NewWriter(file)
+	w.name = file.Name()
+	w.size = 0
+	w.number = num
+}
+
+func (w *splitWriter) WriteLine(line []byte) error {
+	lineSize := int64(len(line) + 1)
+	if w.size > 0 && w.size+lineSize > w.maxSize {
+		if err := w.open(w.number + 1); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.buf.Write(line); err != nil {
+		return fmt.Errorf("failed to write combination: %w", err)
+	}
+	if err := w.buf.WriteByte('\n'); err != nil {
+		return fmt.Errorf("failed to write combination: %w", err)
+	}
+
+	w.size += lineSize
+	return nil
+}
+
+func (w *splitWriter) Name() string {
+	return w.name
+}
+
+func (w *splitWriter) FileNumber() int {
+	return w.number
+}
+
+// Size returns the number of bytes written to the current file.
+func (w *splitWriter) Size() int64 {
+	return w.size
+}
+
+func (w *splitWriter) Flush() error {
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write combination: %w", err)
+	}
+	return nil
+}
+
+// Sync flushes the buffered lines and commits the current file to stable
+// storage, so a checkpoint saved next never points past data lost in a crash.
+func (w *splitWriter) Sync() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync output file: %w", err)
+	}
+	return nil
+}
+
+func (w *splitWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+
+	file := w.file
+	w.file = nil
+	if err := w.buf.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write combination: %w", err)
+	}
+	// Files before the current one are not synced by checkpoints
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync output file: %w", err)
+	}
+	return file.Close()
+}
+
+func (w *splitWriter) Close() error {
+	return w.closeFile()
+}
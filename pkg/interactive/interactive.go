@@ -50,10 +50,12 @@ func (m *Model) startGeneration() error {
 	}
 
 	passwordCount := gen.GetPasswordCount()
-	totalCombinations := gen.CalculateTotalCombinations()
-
 	fmt.Printf("Loaded %d passwords\n", passwordCount)
-	fmt.Printf("Total combinations to generate: %d\n", totalCombinations)
+
+	fmt.Printf("Total combinations to generate: %s\n", gen.Keyspace())
+	if _, err := gen.CalculateTotalCombinations(); err != nil {
+		return err
+	}
 
 	progressChan := make(chan generator.ProgressInfo)
 	go func() {